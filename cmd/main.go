@@ -4,16 +4,28 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strings"
-	"time"
+	"path/filepath"
 
 	"github.com/niklvdanya/BiathlonTracker/internal/config"
 	"github.com/niklvdanya/BiathlonTracker/internal/event"
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
 	"github.com/niklvdanya/BiathlonTracker/internal/report"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
+// stdioFile names stdin as the events file and stdout as an output file.
+const stdioFile = "-"
+
+// documentFormats write the event log and the final report as separate
+// documents, which cannot share one stream.
+var documentFormats = map[string]bool{
+	"json": true,
+	"csv":  true,
+	"xml":  true,
+}
+
 type BiathlonService struct {
 	Parser    event.EventParser
 	Processor event.EventProcessor
@@ -32,88 +44,204 @@ func NewBiathlonService(parser event.EventParser, processor event.EventProcessor
 
 func main() {
 	configFileFlag := flag.String("config", "config.json", "Path to configuration file")
-	eventsFileFlag := flag.String("events", "events.txt", "Path to events file")
+	eventsFileFlag := flag.String("events", "events.txt", "Path to events file (.gz supported, - for stdin)")
 	parallelFlag := flag.Bool("parallel", false, "Use parallel processing")
+	strictFlag := flag.Bool("strict", false, "Fail on the first malformed event line")
+	inputFormatFlag := flag.String("input-format", "", "Events format: text, ndjson, json or csv (detected from the file extension by default)")
+	outFlag := flag.String("out", stdioFile, "Path to final report file (- for stdout)")
+	logOutFlag := flag.String("log-out", stdioFile, "Path to event log file (- for stdout)")
+	lapsOutFlag := flag.String("laps-out", "", "Path to lap times file for -format=csv")
+	formatFlag := flag.String("format", "text", "Output format: text, json, csv, html, md or xml")
 	flag.Parse()
 
 	if !checkFiles(*configFileFlag, *eventsFileFlag) {
-		return
+		os.Exit(1)
 	}
 
-	parser := &event.DefaultEventParser{}
-	processor := &event.DefaultEventProcessor{}
-	reporter := &report.DefaultReporter{}
-
 	cfg, err := config.Load(*configFileFlag)
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		return
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	clock, err := cfg.Clock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
 	}
 
+	if err := checkOutputFlags(*formatFlag, *outFlag, *logOutFlag, *lapsOutFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := checkOutputFiles(*outFlag, *logOutFlag, *lapsOutFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	reporter, err := newReporter(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	parser := &event.DefaultEventParser{Options: event.ParseOptions{
+		Strict: *strictFlag,
+		Date:   clock.Date(),
+		Format: *inputFormatFlag,
+	}}
+	processor := &event.DefaultEventProcessor{}
+
 	service := NewBiathlonService(parser, processor, reporter, cfg)
 
-	events, err := service.Parser.Parse(*eventsFileFlag)
+	events, parseErrors, err := loadEvents(service.Parser, *eventsFileFlag)
 	if err != nil {
-		fmt.Printf("Error loading events: %v\n", err)
-		return
+		fmt.Fprintf(os.Stderr, "Error loading events: %v\n", err)
+		os.Exit(1)
 	}
 
-	events = handleLostEvents(events)
+	for _, parseErr := range parseErrors {
+		fmt.Fprintf(os.Stderr, "Warning: Skipping invalid event %v\n", parseErr)
+	}
 
 	ctx := context.Background()
-	var competitors map[int]*model.Competitor
+	var result model.ProcessResult
 
 	if *parallelFlag {
-		competitors = event.ProcessEventsParallel(ctx, events, cfg)
+		result = event.ProcessEventsParallel(ctx, events, cfg)
 	} else {
-		competitors = service.Processor.Process(ctx, events, cfg)
+		result = service.Processor.Process(ctx, events, cfg)
 	}
 
-	service.Reporter.OutputLog(events)
-	service.Reporter.OutputFinalReport(competitors, cfg)
-}
+	// The HTML results page already embeds the event log.
+	_, htmlReport := service.Reporter.(*report.HTMLReporter)
+	if !htmlReport || *logOutFlag != stdioFile {
+		if err := writeOutput(*logOutFlag, func(w io.Writer) error {
+			return service.Reporter.OutputLog(w, result)
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing event log: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-func checkFiles(configFile, eventsFile string) bool {
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		fmt.Printf("Ошибка: файл %s не найден\n", configFile)
-		return false
+	if err := writeOutput(*outFlag, func(w io.Writer) error {
+		return service.Reporter.OutputFinalReport(w, result, cfg)
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing final report: %v\n", err)
+		os.Exit(1)
 	}
-	if _, err := os.Stat(eventsFile); os.IsNotExist(err) {
-		fmt.Printf("Ошибка: файл %s не найден\n", eventsFile)
-		return false
+
+	if csvReporter, ok := service.Reporter.(*report.CSVReporter); ok {
+		if err := writeOutput(*lapsOutFlag, func(w io.Writer) error {
+			return csvReporter.OutputLaps(w, result)
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing lap times: %v\n", err)
+			os.Exit(1)
+		}
 	}
-	return true
 }
 
-func handleLostEvents(events []model.Event) []model.Event {
-	for i := range events {
-		if events[i].EventID == model.EventLostInForest && strings.Contains(events[i].ExtraParams, model.LostInForestText) {
-			shotCount, hasEventID6 := countShotsForCompetitor(events, events[i].CompetitorID)
-
-			if shotCount < 5 && !hasEventID6 {
-				missEvent := model.Event{
-					Time:         events[i].Time.Add(-time.Second * 5),
-					EventID:      model.EventShot,
-					CompetitorID: events[i].CompetitorID,
-					ExtraParams:  model.ShotTarget3,
-				}
-				events = append(events, missEvent)
-			}
+func newReporter(format string) (report.Reporter, error) {
+	switch format {
+	case "text":
+		return &report.DefaultReporter{}, nil
+	case "json":
+		return &report.JSONReporter{}, nil
+	case "csv":
+		return &report.CSVReporter{}, nil
+	case "html":
+		return &report.HTMLReporter{}, nil
+	case "md":
+		return &report.MarkdownReporter{}, nil
+	case "xml":
+		return &report.XMLReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// checkOutputFlags makes sure every document the format writes has a
+// destination of its own: -format=csv writes lap times to -laps-out, and at
+// most one document of a documentFormats format may go to stdout.
+func checkOutputFlags(format, outFile, logOutFile, lapsOutFile string) error {
+	if format == "csv" && lapsOutFile == "" {
+		return fmt.Errorf("-format=csv writes lap times to a separate file, set -laps-out")
+	}
+	if format != "csv" && lapsOutFile != "" {
+		return fmt.Errorf("-laps-out is only written with -format=csv, not -format=%s", format)
+	}
+
+	if !documentFormats[format] {
+		return nil
+	}
+	stdout := 0
+	for _, outFile := range []string{outFile, logOutFile, lapsOutFile} {
+		if outFile == stdioFile {
+			stdout++
 		}
 	}
-	return events
+	if stdout > 1 {
+		return fmt.Errorf("-format=%s writes each output as a separate document, send at most one of them to stdout", format)
+	}
+	return nil
 }
 
-func countShotsForCompetitor(events []model.Event, competitorID int) (int, bool) {
-	shotCount := 0
-	hasEventID6 := false
-	for j := range events {
-		if events[j].EventID == model.EventShot && events[j].CompetitorID == competitorID {
-			shotCount++
-			if events[j].ExtraParams == model.ShotTarget3 {
-				hasEventID6 = true
-			}
+// checkOutputFiles rejects two outputs naming the same file, which would
+// leave only the one written last.
+func checkOutputFiles(outFiles ...string) error {
+	seen := make(map[string]string)
+	for _, outFile := range outFiles {
+		if outFile == "" || outFile == stdioFile {
+			continue
+		}
+		path, err := filepath.Abs(outFile)
+		if err != nil {
+			return err
+		}
+		if previous, ok := seen[path]; ok {
+			return fmt.Errorf("output files %s and %s are the same file", previous, outFile)
 		}
+		seen[path] = outFile
+	}
+	return nil
+}
+
+func writeOutput(outFile string, write func(w io.Writer) error) error {
+	if outFile == stdioFile {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func loadEvents(parser event.EventParser, eventsFile string) ([]model.Event, []utils.ParseError, error) {
+	if eventsFile == stdioFile {
+		return parser.ParseReader(os.Stdin)
 	}
-	return shotCount, hasEventID6
+	return parser.Parse(eventsFile)
+}
+
+func checkFiles(configFile, eventsFile string) bool {
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Ошибка: файл %s не найден\n", configFile)
+		return false
+	}
+	if eventsFile == stdioFile {
+		return true
+	}
+	if _, err := os.Stat(eventsFile); os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Ошибка: файл %s не найден\n", eventsFile)
+		return false
+	}
+	return true
 }
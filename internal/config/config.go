@@ -2,17 +2,25 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"strconv"
+	"time"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
+const DefaultMaxPenaltySpeed = 8.0
+
 type Config struct {
-	Laps        int    `json:"laps"`
-	LapLen      int    `json:"lapLen"`
-	PenaltyLen  int    `json:"penaltyLen"`
-	FiringLines int    `json:"firingLines"`
-	Start       string `json:"start"`
-	StartDelta  string `json:"startDelta"`
+	Date            string  `json:"date"`
+	Laps            int     `json:"laps"`
+	LapLen          int     `json:"lapLen"`
+	PenaltyLen      int     `json:"penaltyLen"`
+	FiringLines     int     `json:"firingLines"`
+	Start           string  `json:"start"`
+	StartDelta      string  `json:"startDelta"`
+	MaxPenaltySpeed float64 `json:"maxPenaltySpeed"`
 }
 
 func Load(filename string) (Config, error) {
@@ -28,6 +36,10 @@ func Load(filename string) (Config, error) {
 		return config, err
 	}
 
+	if date, exists := os.LookupEnv("BIATHLON_DATE"); exists {
+		config.Date = date
+	}
+
 	if laps, exists := os.LookupEnv("BIATHLON_LAPS"); exists {
 		if value, err := strconv.Atoi(laps); err == nil {
 			config.Laps = value
@@ -60,5 +72,67 @@ func Load(filename string) (Config, error) {
 		config.StartDelta = startDelta
 	}
 
+	if maxPenaltySpeed, exists := os.LookupEnv("BIATHLON_MAX_PENALTY_SPEED"); exists {
+		if value, err := strconv.ParseFloat(maxPenaltySpeed, 64); err == nil {
+			config.MaxPenaltySpeed = value
+		}
+	}
+
+	if err := config.validate(); err != nil {
+		return config, err
+	}
+
 	return config, nil
 }
+
+// validate checks the clock strings that are set. Start and startDelta are
+// optional: without a start, draws are not checked against it, and without
+// a startDelta the start window is empty.
+func (c Config) validate() error {
+	clock, err := c.Clock()
+	if err != nil {
+		return err
+	}
+	if c.Start != "" {
+		if _, err := clock.Parse(c.Start); err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+	}
+	if c.StartDelta != "" {
+		if _, err := c.StartDeltaDuration(); err != nil {
+			return fmt.Errorf("startDelta: %w", err)
+		}
+	}
+	return nil
+}
+
+// Clock anchors clock strings to the competition date, or to today when
+// no date is configured.
+func (c Config) Clock() (*utils.Clock, error) {
+	if c.Date == "" {
+		return utils.NewClock(time.Now()), nil
+	}
+
+	date, err := time.Parse(utils.DateFormat, c.Date)
+	if err != nil {
+		return nil, fmt.Errorf("date: %w: %q", utils.ErrInvalidTimeFormat, c.Date)
+	}
+	return utils.NewClock(date), nil
+}
+
+func (c Config) StartDeltaDuration() (time.Duration, error) {
+	if c.StartDelta == "" {
+		return 0, nil
+	}
+	return utils.ParseClockDuration(c.StartDelta)
+}
+
+// PenaltySpeedLimit is the fastest plausible penalty loop speed in m/s; a
+// penalty stint shorter than the owed loops allow at this speed is a
+// shortfall.
+func (c Config) PenaltySpeedLimit() float64 {
+	if c.MaxPenaltySpeed > 0 {
+		return c.MaxPenaltySpeed
+	}
+	return DefaultMaxPenaltySpeed
+}
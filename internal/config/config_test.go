@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadOptionalStart(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"laps": 2, "lapLen": 3651, "penaltyLen": 50, "firingLines": 1}`))
+	if err != nil {
+		t.Fatalf("expected a config without start and startDelta to load, got %v", err)
+	}
+
+	startDelta, err := cfg.StartDeltaDuration()
+	if err != nil || startDelta != 0 {
+		t.Errorf("expected an empty start window, got %v, %v", startDelta, err)
+	}
+
+	cfg, err = Load(writeConfig(t, `{"laps": 2, "start": "09:30:00.000", "startDelta": "00:00:30"}`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if startDelta, _ := cfg.StartDeltaDuration(); startDelta != 30*time.Second {
+		t.Errorf("expected a 30s start window, got %v", startDelta)
+	}
+}
+
+func TestLoadInvalidTimes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"date", `{"date": "01.01.2025"}`},
+		{"start", `{"start": "9:30"}`},
+		{"startDelta", `{"start": "09:30:00.000", "startDelta": "30s"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if !errors.Is(err, utils.ErrInvalidTimeFormat) {
+				t.Errorf("expected ErrInvalidTimeFormat, got %v", err)
+			}
+		})
+	}
+}
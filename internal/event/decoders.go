@@ -0,0 +1,287 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+const (
+	FormatText   = "text"
+	FormatNDJSON = "ndjson"
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+)
+
+// errHeaderLine marks a line that holds column names rather than an event.
+var errHeaderLine = errors.New("header line")
+
+// lineDecoder turns one non-empty input line into an event. Errors in the
+// line itself are returned as utils.ParseError; the scanner fills in the
+// line number.
+type lineDecoder func(line string, clock *utils.Clock) (model.Event, error)
+
+var lineDecoders = map[string]lineDecoder{
+	FormatText:   parseEvent,
+	FormatNDJSON: decodeJSONEvent,
+	FormatCSV:    decodeCSVEvent,
+}
+
+// DetectFormat picks the input format from a file extension, looking past
+// a trailing .gz; anything unrecognised is the text format.
+func DetectFormat(filename string) string {
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, ".gz")))
+	switch ext {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	default:
+		return FormatText
+	}
+}
+
+func decoderFor(format string) (lineDecoder, error) {
+	if format == "" {
+		format = FormatText
+	}
+	decode, ok := lineDecoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+	return decode, nil
+}
+
+type jsonEvent struct {
+	Time       *string         `json:"time"`
+	Event      *int            `json:"event"`
+	Competitor *int            `json:"competitor"`
+	Params     json.RawMessage `json:"params"`
+}
+
+// decodeJSONEvent reads one NDJSON record such as
+// {"time": "09:05:59.867", "event": 1, "competitor": 1}. Params may be a
+// string or a number.
+func decodeJSONEvent(line string, clock *utils.Clock) (model.Event, error) {
+	var event model.Event
+
+	var record jsonEvent
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return event, newParseError(line, jsonErrorColumn(err), fmt.Errorf("%w: %v", utils.ErrInvalidEventFormat, err))
+	}
+
+	if record.Time == nil {
+		return event, newParseError(line, 1, fmt.Errorf("%w: missing time", utils.ErrInvalidTimeFormat))
+	}
+	eventTime, err := clock.Next(*record.Time)
+	if err != nil {
+		return event, newParseError(line, 1, err)
+	}
+
+	if record.Event == nil {
+		return event, newParseError(line, 1, fmt.Errorf("%w: missing", utils.ErrInvalidEventID))
+	}
+	if err := checkEventID(*record.Event); err != nil {
+		return event, newParseError(line, 1, fmt.Errorf("%w: %d", err, *record.Event))
+	}
+	if record.Competitor == nil || *record.Competitor <= 0 {
+		return event, newParseError(line, 1, fmt.Errorf("%w: %s", utils.ErrInvalidCompetitorID, describeJSONInt(record.Competitor)))
+	}
+
+	params, err := jsonParams(record.Params)
+	if err != nil {
+		return event, newParseError(line, 1, err)
+	}
+
+	event.Time = eventTime
+	event.EventID = *record.Event
+	event.CompetitorID = *record.Competitor
+	event.ExtraParams = params
+	return event, nil
+}
+
+func jsonParams(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var params string
+	if err := json.Unmarshal(raw, &params); err == nil {
+		return params, nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number.String(), nil
+	}
+
+	return "", fmt.Errorf("%w: params must be a string or a number, got %s", utils.ErrInvalidEventFormat, raw)
+}
+
+func jsonErrorColumn(err error) int {
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		return int(syntaxErr.Offset)
+	}
+	return 1
+}
+
+func describeJSONInt(value *int) string {
+	if value == nil {
+		return "missing"
+	}
+	return strconv.Itoa(*value)
+}
+
+// decodeCSVEvent reads one "time,event,competitor[,params]" record. A
+// header line starting with "time" yields no event.
+// scanJSON yields the records of a JSON array export such as
+// [{"time": "09:05:59.867", "event": 1, "competitor": 1}, ...]; input that
+// is not an array is read as NDJSON. A syntax error in the array ends the
+// sequence, since no later record can be located.
+func scanJSON(r io.Reader, clock *utils.Clock) iter.Seq2[model.Event, error] {
+	return func(yield func(model.Event, error) bool) {
+		lines := &lineCounter{r: r}
+		buffered := bufio.NewReader(lines)
+		if !startsWithArray(buffered) {
+			scanEvents(buffered, clock, decodeJSONEvent)(yield)
+			return
+		}
+
+		decoder := json.NewDecoder(buffered)
+		if _, err := decoder.Token(); err != nil {
+			yield(model.Event{}, err)
+			return
+		}
+
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				var syntaxErr *json.SyntaxError
+				if errors.As(err, &syntaxErr) {
+					line, column := lines.position(max(syntaxErr.Offset-1, 0))
+					err = utils.ParseError{Line: line, Column: column, Err: fmt.Errorf("%w: %v", utils.ErrInvalidEventFormat, err)}
+				}
+				yield(model.Event{}, err)
+				return
+			}
+
+			line, _ := lines.position(decoder.InputOffset() - int64(len(raw)))
+			event, err := decodeRecord(string(raw), line, clock, decodeJSONEvent)
+			if err != nil {
+				event = model.Event{}
+			}
+			if !yield(event, err) {
+				return
+			}
+		}
+	}
+}
+
+func startsWithArray(r *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		peeked, err := r.Peek(n)
+		if err != nil {
+			return false
+		}
+		if c := peeked[n-1]; c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c == '['
+		}
+	}
+}
+
+// lineCounter records where each line of the stream read through it starts,
+// so that byte offsets can be reported as lines and columns.
+type lineCounter struct {
+	r          io.Reader
+	read       int64
+	lineStarts []int64
+}
+
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			c.lineStarts = append(c.lineStarts, c.read+int64(i)+1)
+		}
+	}
+	c.read += int64(n)
+	return n, err
+}
+
+// position returns the 1-based line and column of a byte offset.
+func (c *lineCounter) position(offset int64) (int, int) {
+	i, _ := slices.BinarySearch(c.lineStarts, offset+1)
+	lineStart := int64(0)
+	if i > 0 {
+		lineStart = c.lineStarts[i-1]
+	}
+	return i + 1, int(offset-lineStart) + 1
+}
+
+func decodeCSVEvent(line string, clock *utils.Clock) (model.Event, error) {
+	var event model.Event
+
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.FieldsPerRecord = -1
+	record, err := reader.Read()
+	if err != nil {
+		return event, newParseError(line, 1, fmt.Errorf("%w: %v", utils.ErrInvalidEventFormat, err))
+	}
+
+	column := func(i int) int {
+		_, col := reader.FieldPos(i)
+		return col
+	}
+
+	timeStr := strings.TrimSpace(record[0])
+	if strings.EqualFold(timeStr, "time") {
+		return event, errHeaderLine
+	}
+
+	if len(record) < 3 {
+		return event, newParseError(line, len(line)+1, utils.ErrInvalidEventFormat)
+	}
+
+	eventTime, err := clock.Next(timeStr)
+	if err != nil {
+		return event, newParseError(line, column(0), err)
+	}
+
+	eventID, err := strconv.Atoi(strings.TrimSpace(record[1]))
+	if err != nil {
+		err = utils.ErrInvalidEventID
+	} else {
+		err = checkEventID(eventID)
+	}
+	if err != nil {
+		return event, newParseError(line, column(1), fmt.Errorf("%w: %q", err, record[1]))
+	}
+
+	competitorID, err := strconv.Atoi(strings.TrimSpace(record[2]))
+	if err != nil || competitorID <= 0 {
+		return event, newParseError(line, column(2), fmt.Errorf("%w: %q", utils.ErrInvalidCompetitorID, record[2]))
+	}
+
+	event.Time = eventTime
+	event.EventID = eventID
+	event.CompetitorID = competitorID
+	if len(record) > 3 {
+		event.ExtraParams = strings.TrimSpace(strings.Join(record[3:], ","))
+	}
+	return event, nil
+}
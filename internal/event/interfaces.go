@@ -2,27 +2,36 @@ package event
 
 import (
 	"context"
+	"io"
 
 	"github.com/niklvdanya/BiathlonTracker/internal/config"
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
 type EventParser interface {
-	Parse(filename string) ([]model.Event, error)
+	Parse(filename string) ([]model.Event, []utils.ParseError, error)
+	ParseReader(r io.Reader) ([]model.Event, []utils.ParseError, error)
 }
 
 type EventProcessor interface {
-	Process(ctx context.Context, events []model.Event, cfg config.Config) map[int]*model.Competitor
+	Process(ctx context.Context, events []model.Event, cfg config.Config) model.ProcessResult
 }
 
-type DefaultEventParser struct{}
+type DefaultEventParser struct {
+	Options ParseOptions
+}
+
+func (p *DefaultEventParser) Parse(filename string) ([]model.Event, []utils.ParseError, error) {
+	return LoadEvents(filename, p.Options)
+}
 
-func (p *DefaultEventParser) Parse(filename string) ([]model.Event, error) {
-	return LoadEvents(filename)
+func (p *DefaultEventParser) ParseReader(r io.Reader) ([]model.Event, []utils.ParseError, error) {
+	return ReadEvents(r, p.Options)
 }
 
 type DefaultEventProcessor struct{}
 
-func (p *DefaultEventProcessor) Process(ctx context.Context, events []model.Event, cfg config.Config) map[int]*model.Competitor {
+func (p *DefaultEventProcessor) Process(ctx context.Context, events []model.Event, cfg config.Config) model.ProcessResult {
 	return ProcessEvents(ctx, events, cfg)
 }
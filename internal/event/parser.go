@@ -1,7 +1,12 @@
 package event
 
 import (
+	"bufio"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 	"strconv"
 	"strings"
@@ -11,49 +16,158 @@ import (
 	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
-func LoadEvents(filename string) ([]model.Event, error) {
-	data, err := os.ReadFile(filename)
+type ParseOptions struct {
+	// Strict stops parsing at the first malformed line instead of
+	// collecting it into the returned parse errors.
+	Strict bool
+	// Date is the competition date event times are anchored to; today is
+	// used when it is zero.
+	Date time.Time
+	// Format is one of FormatText, FormatNDJSON, FormatJSON or FormatCSV.
+	// When empty,
+	// LoadEvents detects it from the file extension and ReadEvents assumes
+	// FormatText.
+	Format string
+}
+
+func (o ParseOptions) clock() *utils.Clock {
+	if o.Date.IsZero() {
+		return utils.NewClock(time.Now())
+	}
+	return utils.NewClock(o.Date)
+}
+
+func LoadEvents(filename string, opts ParseOptions) ([]model.Event, []utils.ParseError, error) {
+	file, err := os.Open(filename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer file.Close()
 
-	lines := strings.Split(string(data), "\n")
-	events := make([]model.Event, 0, len(lines))
+	if opts.Format == "" {
+		opts.Format = DetectFormat(filename)
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	var r io.Reader = file
+	if strings.HasSuffix(filename, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, nil, err
 		}
+		defer gz.Close()
+		r = gz
+	}
 
-		event, err := parseEvent(line)
-		if err != nil {
-			fmt.Printf("Warning: Skipping invalid event line: %s, error: %v\n", line, err)
-			continue
+	return ReadEvents(r, opts)
+}
+
+func ReadEvents(r io.Reader, opts ParseOptions) ([]model.Event, []utils.ParseError, error) {
+	var events []model.Event
+	var parseErrors []utils.ParseError
+
+	for event, err := range ScanEvents(r, opts) {
+		var parseErr utils.ParseError
+		switch {
+		case err == nil:
+			events = append(events, event)
+		case errors.As(err, &parseErr) && !opts.Strict:
+			parseErrors = append(parseErrors, parseErr)
+		default:
+			return nil, parseErrors, err
+		}
+	}
+
+	return events, parseErrors, nil
+}
+
+// ScanEvents yields events from r in the format opts.Format (FormatText
+// when empty) one line at a time, so the input is never held in memory as a
+// whole. A malformed line is yielded as a utils.ParseError and scanning goes
+// on if the consumer continues; a read error from r or an unknown format
+// ends the sequence. opts.Strict is left to the consumer.
+func ScanEvents(r io.Reader, opts ParseOptions) iter.Seq2[model.Event, error] {
+	if opts.Format == FormatJSON {
+		return scanJSON(r, opts.clock())
+	}
+
+	decode, err := decoderFor(opts.Format)
+	if err != nil {
+		return func(yield func(model.Event, error) bool) {
+			yield(model.Event{}, err)
+		}
+	}
+	// The clock tracks midnight rollovers, so each stream gets a fresh one.
+	return scanEvents(r, opts.clock(), decode)
+}
+
+func scanEvents(r io.Reader, clock *utils.Clock, decode lineDecoder) iter.Seq2[model.Event, error] {
+	return func(yield func(model.Event, error) bool) {
+		scanner := bufio.NewScanner(r)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			event, err := decodeRecord(line, lineNum, clock, decode)
+			if errors.Is(err, errHeaderLine) {
+				continue
+			}
+			if err != nil {
+				if !yield(model.Event{}, err) {
+					return
+				}
+				continue
+			}
+
+			if !yield(event, nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(model.Event{}, err)
 		}
+	}
+}
 
-		events = append(events, event)
+// decodeRecord decodes one record that starts on line lineNum and attaches
+// its payload.
+func decodeRecord(record string, lineNum int, clock *utils.Clock, decode lineDecoder) (model.Event, error) {
+	event, err := decode(record, clock)
+	if err == nil {
+		event.Payload, err = decodePayload(event, clock)
+		if err != nil {
+			err = newParseError(record, paramsColumn(record, event.ExtraParams), err)
+		}
 	}
 
-	return events, nil
+	var parseErr utils.ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.Line = lineNum
+		err = parseErr
+	}
+	return event, err
 }
 
-func parseEvent(line string) (model.Event, error) {
+func parseEvent(line string, clock *utils.Clock) (model.Event, error) {
 	var event model.Event
 
-	timeStr, remaining, err := extractTimeString(line)
+	timeStr, timeColumn, detailsStart, err := extractTimeString(line)
 	if err != nil {
-		return event, err
+		return event, newParseError(line, 1, err)
 	}
 
-	eventTime, err := parseTimeString(timeStr)
+	eventTime, err := clock.Next(timeStr)
 	if err != nil {
-		return event, err
+		return event, newParseError(line, timeColumn, err)
 	}
 
 	event.Time = eventTime
 
-	eventID, competitorID, extraParams, err := parseEventDetails(remaining)
+	eventID, competitorID, extraParams, err := parseEventDetails(line, detailsStart)
 	if err != nil {
 		return event, err
 	}
@@ -65,57 +179,78 @@ func parseEvent(line string) (model.Event, error) {
 	return event, nil
 }
 
-func extractTimeString(line string) (string, string, error) {
+func newParseError(line string, column int, err error) utils.ParseError {
+	return utils.ParseError{
+		Column: column,
+		Raw:    line,
+		Err:    err,
+	}
+}
+
+func extractTimeString(line string) (string, int, int, error) {
 	timeStart := strings.Index(line, "[")
 	timeEnd := strings.Index(line, "]")
 	if timeStart == -1 || timeEnd == -1 || timeStart >= timeEnd {
-		return "", "", utils.ErrInvalidTimeFormat
+		return "", 0, 0, utils.ErrInvalidTimeFormat
 	}
 
-	timeStr := line[timeStart+1 : timeEnd]
-	remaining := strings.TrimSpace(line[timeEnd+1:])
-
-	return timeStr, remaining, nil
+	return line[timeStart+1 : timeEnd], timeStart + 2, timeEnd + 1, nil
 }
 
-func parseTimeString(timeStr string) (time.Time, error) {
-	now := time.Now()
-	baseDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
-
-	eventTime, err := time.Parse(model.TimeFormat, timeStr)
-	if err != nil {
-		return time.Time{}, err
+func parseEventDetails(line string, start int) (int, int, string, error) {
+	fields := splitFields(line, start)
+	if len(fields) < 2 {
+		return 0, 0, "", newParseError(line, len(line)+1, utils.ErrInvalidEventFormat)
 	}
 
-	eventTime = baseDate.Add(
-		time.Duration(eventTime.Hour())*time.Hour +
-			time.Duration(eventTime.Minute())*time.Minute +
-			time.Duration(eventTime.Second())*time.Second +
-			time.Duration(eventTime.Nanosecond()))
-
-	return eventTime, nil
-}
-
-func parseEventDetails(text string) (int, int, string, error) {
-	parts := strings.Fields(text)
-	if len(parts) < 2 {
-		return 0, 0, "", utils.ErrInvalidEventFormat
+	eventID, err := strconv.Atoi(fields[0].text)
+	if err != nil {
+		err = utils.ErrInvalidEventID
+	} else {
+		err = checkEventID(eventID)
 	}
-
-	eventID, err := strconv.Atoi(parts[0])
 	if err != nil {
-		return 0, 0, "", err
+		return 0, 0, "", newParseError(line, fields[0].column,
+			fmt.Errorf("%w: %q", err, fields[0].text))
 	}
 
-	competitorID, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return 0, 0, "", err
+	competitorID, err := strconv.Atoi(fields[1].text)
+	if err != nil || competitorID <= 0 {
+		return 0, 0, "", newParseError(line, fields[1].column,
+			fmt.Errorf("%w: %q", utils.ErrInvalidCompetitorID, fields[1].text))
 	}
 
 	var extraParams string
-	if len(parts) > 2 {
-		extraParams = strings.Join(parts[2:], " ")
+	if len(fields) > 2 {
+		parts := make([]string, 0, len(fields)-2)
+		for _, f := range fields[2:] {
+			parts = append(parts, f.text)
+		}
+		extraParams = strings.Join(parts, " ")
 	}
 
 	return eventID, competitorID, extraParams, nil
 }
+
+type field struct {
+	text   string
+	column int
+}
+
+// splitFields works like strings.Fields on line[start:] but keeps the
+// 1-based column of every field for error reporting.
+func splitFields(line string, start int) []field {
+	var fields []field
+	fieldStart := -1
+	for i := start; i <= len(line); i++ {
+		isSpace := i == len(line) || line[i] == ' ' || line[i] == '\t'
+		switch {
+		case !isSpace && fieldStart == -1:
+			fieldStart = i
+		case isSpace && fieldStart != -1:
+			fields = append(fields, field{text: line[fieldStart:i], column: fieldStart + 1})
+			fieldStart = -1
+		}
+	}
+	return fields
+}
@@ -1,10 +1,17 @@
 package event
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
 func TestEventParsing(t *testing.T) {
@@ -54,7 +61,7 @@ func TestEventParsing(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseEvent(tt.input)
+			result, err := parseEvent(tt.input, utils.NewClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
 
 			if tt.wantErr {
 				if err == nil {
@@ -104,7 +111,7 @@ func TestLoadEvents(t *testing.T) {
 		t.Fatalf("failed to close temp file: %v", err)
 	}
 
-	events, err := LoadEvents(tempFile.Name())
+	events, _, err := LoadEvents(tempFile.Name(), ParseOptions{})
 	if err != nil {
 		t.Fatalf("LoadEvents failed: %v", err)
 	}
@@ -120,3 +127,368 @@ func TestLoadEvents(t *testing.T) {
 		}
 	}
 }
+
+func TestReadEvents(t *testing.T) {
+	input := "[09:05:59.867] 1 1\r\n\n[09:15:00.841] 2 1 09:30:00.000\nbroken line\n[09:29:45.734] 3 1"
+
+	events, parseErrors, err := ReadEvents(strings.NewReader(input), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+
+	if len(parseErrors) != 1 || parseErrors[0].Line != 4 {
+		t.Errorf("expected one parse error on line 4, got %v", parseErrors)
+	}
+
+	expectedIDs := []int{model.EventRegistration, model.EventSetStartTime, model.EventStartLine}
+	if len(events) != len(expectedIDs) {
+		t.Fatalf("expected %d events, got %d", len(expectedIDs), len(events))
+	}
+	for i, e := range events {
+		if e.EventID != expectedIDs[i] {
+			t.Errorf("event[%d]: expected EventID %d, got %d", i, expectedIDs[i], e.EventID)
+		}
+	}
+}
+
+func TestScanEventsStopsEarly(t *testing.T) {
+	input := "[09:05:59.867] 1 1\n[09:05:59.900] 1 2\n[09:05:59.950] 1 3\n"
+
+	var ids []int
+	for event, err := range ScanEvents(strings.NewReader(input), ParseOptions{}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, event.CompetitorID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("expected competitors [1 2], got %v", ids)
+	}
+}
+
+func TestLoadGzipEvents(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "events_test_*.txt.gz")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte("[09:05:59.867] 1 1\n[09:15:00.841] 2 1 09:30:00.000\n")); err != nil {
+		t.Fatalf("failed to compress test data: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to compress test data: %v", err)
+	}
+
+	if _, err := tempFile.Write(buf.Bytes()); err != nil {
+		t.Fatalf("failed to write to temp file: %v", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		t.Fatalf("failed to close temp file: %v", err)
+	}
+
+	events, _, err := LoadEvents(tempFile.Name(), ParseOptions{})
+	if err != nil {
+		t.Fatalf("LoadEvents failed: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Errorf("expected 2 events, got %d", len(events))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		column int
+		err    error
+	}{
+		{
+			name:   "MissingBrackets",
+			input:  "09:05:59.867 1 1",
+			column: 1,
+			err:    utils.ErrInvalidTimeFormat,
+		},
+		{
+			name:   "BadTime",
+			input:  "[09:05:59] 1 1",
+			column: 2,
+			err:    utils.ErrInvalidTimeFormat,
+		},
+		{
+			name:   "BadEventID",
+			input:  "[09:05:59.867] x 1",
+			column: 16,
+			err:    utils.ErrInvalidEventID,
+		},
+		{
+			name:   "BadCompetitorID",
+			input:  "[09:05:59.867] 1  -4",
+			column: 19,
+			err:    utils.ErrInvalidCompetitorID,
+		},
+		{
+			name:   "MissingCompetitor",
+			input:  "[09:05:59.867] 1",
+			column: 17,
+			err:    utils.ErrInvalidEventFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseEvent(tt.input, utils.NewClock(time.Now()))
+
+			var parseErr utils.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError, got %v", err)
+			}
+
+			if parseErr.Column != tt.column {
+				t.Errorf("Column mismatch: got %d, want %d", parseErr.Column, tt.column)
+			}
+
+			if parseErr.Raw != tt.input {
+				t.Errorf("Raw mismatch: got %q, want %q", parseErr.Raw, tt.input)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, parseErr.Err)
+			}
+		})
+	}
+}
+
+func TestStrictParsing(t *testing.T) {
+	input := "[09:05:59.867] 1 1\n[09:15:00.841] two 1\n[09:29:45.734] 3 1\n[09:30:01.005] 4 x\n"
+
+	events, parseErrors, err := ReadEvents(strings.NewReader(input), ParseOptions{Strict: true})
+	if events != nil {
+		t.Errorf("expected no events in strict mode, got %d", len(events))
+	}
+	if len(parseErrors) != 0 {
+		t.Errorf("expected no collected parse errors in strict mode, got %v", parseErrors)
+	}
+
+	var parseErr utils.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if parseErr.Line != 2 || !errors.Is(err, utils.ErrInvalidEventID) {
+		t.Errorf("expected invalid event ID on line 2, got %v", parseErr)
+	}
+
+	events, parseErrors, err = ReadEvents(strings.NewReader(input), ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error in lenient mode: %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("expected 2 events in lenient mode, got %d", len(events))
+	}
+	if len(parseErrors) != 2 || parseErrors[0].Line != 2 || parseErrors[1].Line != 4 {
+		t.Errorf("expected parse errors on lines 2 and 4, got %v", parseErrors)
+	}
+}
+
+func TestInputFormats(t *testing.T) {
+	inputs := map[string]string{
+		FormatText: "[09:05:59.867] 1 1\n" +
+			"[09:15:00.841] 2 1 09:30:00.000\n" +
+			"[09:49:31.659] 5 1 1\n" +
+			"[09:59:05.321] 11 1 Lost in the forest\n",
+		FormatNDJSON: `{"time": "09:05:59.867", "event": 1, "competitor": 1}` + "\n" +
+			`{"time": "09:15:00.841", "event": 2, "competitor": 1, "params": "09:30:00.000"}` + "\n" +
+			`{"time": "09:49:31.659", "event": 5, "competitor": 1, "params": 1}` + "\n" +
+			`{"time": "09:59:05.321", "event": 11, "competitor": 1, "params": "Lost in the forest"}` + "\n",
+		FormatJSON: "[\n" +
+			`  {"time": "09:05:59.867", "event": 1, "competitor": 1},` + "\n" +
+			`  {"time": "09:15:00.841", "event": 2, "competitor": 1, "params": "09:30:00.000"},` + "\n" +
+			`  {` + "\n" +
+			`    "time": "09:49:31.659", "event": 5, "competitor": 1, "params": 1` + "\n" +
+			`  },` + "\n" +
+			`  {"time": "09:59:05.321", "event": 11, "competitor": 1, "params": "Lost in the forest"}` + "\n" +
+			"]\n",
+		FormatCSV: "time,event,competitor,params\n" +
+			"09:05:59.867,1,1,\n" +
+			"09:15:00.841,2,1,09:30:00.000\n" +
+			"09:49:31.659,5,1,1\n" +
+			"09:59:05.321,11,1,Lost in the forest\n",
+	}
+
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected, _, err := ReadEvents(strings.NewReader(inputs[FormatText]), ParseOptions{Date: date})
+	if err != nil || len(expected) != 4 {
+		t.Fatalf("failed to read text events: %v, %v", expected, err)
+	}
+
+	for format, input := range inputs {
+		t.Run(format, func(t *testing.T) {
+			events, parseErrors, err := ReadEvents(strings.NewReader(input), ParseOptions{Date: date, Format: format})
+			if err != nil || len(parseErrors) != 0 {
+				t.Fatalf("unexpected errors: %v, %v", err, parseErrors)
+			}
+			if !slices.Equal(events, expected) {
+				t.Errorf("expected %v, got %v", expected, events)
+			}
+		})
+	}
+}
+
+func TestInputFormatErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+		column int
+		err    error
+	}{
+		{format: FormatNDJSON, input: `{"time": "09:05:59.867", "event": 0, "competitor": 1}`, column: 1, err: utils.ErrInvalidEventID},
+		{format: FormatNDJSON, input: `{"time": "09:05:59.867", "event": 1}`, column: 1, err: utils.ErrInvalidCompetitorID},
+		{format: FormatNDJSON, input: `{"time": "09:05:59.867", "event": 1, "competitor": 1, "params": [1]}`, column: 1, err: utils.ErrInvalidEventFormat},
+		{format: FormatNDJSON, input: `{"time": "09:05:59.867",`, column: 24, err: utils.ErrInvalidEventFormat},
+		{format: FormatCSV, input: "09:05:59.867,x,1", column: 14, err: utils.ErrInvalidEventID},
+		{format: FormatCSV, input: "09:05,1,1", column: 1, err: utils.ErrInvalidTimeFormat},
+		{format: FormatCSV, input: "09:05:59.867,1", column: 15, err: utils.ErrInvalidEventFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.input, func(t *testing.T) {
+			_, parseErrors, err := ReadEvents(strings.NewReader(tt.input), ParseOptions{Format: tt.format})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(parseErrors) != 1 {
+				t.Fatalf("expected one parse error, got %v", parseErrors)
+			}
+			if parseErrors[0].Line != 1 || parseErrors[0].Column != tt.column || !errors.Is(parseErrors[0], tt.err) {
+				t.Errorf("expected %v at line 1, column %d, got %v", tt.err, tt.column, parseErrors[0])
+			}
+		})
+	}
+
+	if _, _, err := ReadEvents(strings.NewReader(""), ParseOptions{Format: "yaml"}); err == nil {
+		t.Errorf("expected an error for an unknown input format")
+	}
+}
+
+func TestScanEventsFormat(t *testing.T) {
+	input := "time,event,competitor\n09:05:59.867,1,1\n09:05:59.900,1,x\n"
+
+	var ids []int
+	var parseErrors []utils.ParseError
+	for event, err := range ScanEvents(strings.NewReader(input), ParseOptions{Format: FormatCSV}) {
+		var parseErr utils.ParseError
+		switch {
+		case err == nil:
+			ids = append(ids, event.CompetitorID)
+		case errors.As(err, &parseErr):
+			parseErrors = append(parseErrors, parseErr)
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if !slices.Equal(ids, []int{1}) || len(parseErrors) != 1 || parseErrors[0].Line != 3 {
+		t.Errorf("expected competitor 1 and a parse error on line 3, got %v, %v", ids, parseErrors)
+	}
+
+	var formatErrors []error
+	for _, err := range ScanEvents(strings.NewReader(input), ParseOptions{Format: "yaml"}) {
+		formatErrors = append(formatErrors, err)
+	}
+	if len(formatErrors) != 1 || formatErrors[0] == nil {
+		t.Errorf("expected a single error for an unknown input format, got %v", formatErrors)
+	}
+}
+
+func TestJSONArrayErrors(t *testing.T) {
+	input := "[\n" +
+		`  {"time": "09:05:59.867", "event": 1, "competitor": 1},` + "\n" +
+		`  {"time": "09:05:59.900", "event": 1, "competitor": 0},` + "\n" +
+		`  {"time": "09:05:59.950", "event": 1, "competitor": 3},` + "\n" +
+		`  {"time": "09:06:00.000", "event": 1 "competitor": 4}` + "\n" +
+		"]\n"
+
+	events, parseErrors, err := ReadEvents(strings.NewReader(input), ParseOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].CompetitorID != 1 || events[1].CompetitorID != 3 {
+		t.Errorf("expected competitors 1 and 3, got %v", events)
+	}
+	if len(parseErrors) != 2 ||
+		parseErrors[0].Line != 3 || !errors.Is(parseErrors[0], utils.ErrInvalidCompetitorID) ||
+		parseErrors[1].Line != 5 || parseErrors[1].Column != 39 || !errors.Is(parseErrors[1], utils.ErrInvalidEventFormat) {
+		t.Errorf("expected an invalid competitor on line 3 and a syntax error at 5:39, got %v", parseErrors)
+	}
+
+	// A .json file holding one record per line is read as NDJSON.
+	ndjson := `{"time": "09:05:59.867", "event": 1, "competitor": 1}` + "\n" + `{"time": "09:05:59.900", "event": 1, "competitor": 2}` + "\n"
+	events, parseErrors, err = ReadEvents(strings.NewReader(ndjson), ParseOptions{Format: FormatJSON})
+	if err != nil || len(parseErrors) != 0 || len(events) != 2 {
+		t.Errorf("expected 2 NDJSON events, got %v, %v, %v", events, parseErrors, err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	for filename, format := range map[string]string{
+		"events.txt":       FormatText,
+		"events":           FormatText,
+		"events.ndjson":    FormatNDJSON,
+		"events.jsonl.gz":  FormatNDJSON,
+		"export.json":      FormatJSON,
+		"season/day1.CSV":  FormatCSV,
+		"events.csv.gz":    FormatCSV,
+		"events.backup.gz": FormatText,
+	} {
+		if got := DetectFormat(filename); got != format {
+			t.Errorf("DetectFormat(%q) = %q, want %q", filename, got, format)
+		}
+	}
+}
+
+func TestEventPayloads(t *testing.T) {
+	input := "[23:50:00.000] 2 1 00:10:00.000\n" +
+		"[23:55:00.000] 5 1 2\n" +
+		"[23:55:01.000] 6 1 4\n" +
+		"[23:55:02.000] 6 1 7\n" +
+		"[23:55:03.000] 5 1 first\n" +
+		"[23:59:00.000] 11 1 Lost in the forest\n"
+
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	events, parseErrors, err := ReadEvents(strings.NewReader(input), ParseOptions{Date: date})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []model.Payload{
+		model.StartTimePayload{Time: date.Add(24*time.Hour + 10*time.Minute)},
+		model.FiringRangePayload{Line: 2},
+		model.ShotPayload{Target: 4},
+		model.CommentPayload{Text: "Lost in the forest"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i, event := range events {
+		if event.Payload != expected[i] {
+			t.Errorf("event %d: expected payload %+v, got %+v", i, expected[i], event.Payload)
+		}
+	}
+
+	if len(parseErrors) != 2 {
+		t.Fatalf("expected 2 parse errors, got %v", parseErrors)
+	}
+	for i, want := range []struct{ line, column int }{{4, 20}, {5, 20}} {
+		got := parseErrors[i]
+		if got.Line != want.line || got.Column != want.column || !errors.Is(got, utils.ErrInvalidEventParams) {
+			t.Errorf("expected invalid params at line %d, column %d, got %v", want.line, want.column, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// decodePayload turns the ExtraParams of an incoming event into its typed
+// payload using the decoder registered for the event ID.
+func decodePayload(event model.Event, clock *utils.Clock) (model.Payload, error) {
+	eventType, ok := LookupEventType(event.EventID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", utils.ErrUnknownEventID, event.EventID)
+	}
+	if eventType.Decode == nil {
+		return nil, nil
+	}
+	return eventType.Decode(event, clock)
+}
+
+// decodeStartTime anchors a drawn start time to the day of the draw.
+func decodeStartTime(event model.Event, clock *utils.Clock) (model.Payload, error) {
+	startTime, err := clock.Near(event.Time, event.ExtraParams)
+	if err != nil {
+		return nil, err
+	}
+	return model.StartTimePayload{Time: startTime}, nil
+}
+
+func decodeFiringRange(event model.Event, clock *utils.Clock) (model.Payload, error) {
+	line, err := strconv.Atoi(event.ExtraParams)
+	if err != nil || line < 1 {
+		return nil, fmt.Errorf("%w: firing line %q", utils.ErrInvalidEventParams, event.ExtraParams)
+	}
+	return model.FiringRangePayload{Line: line}, nil
+}
+
+func decodeShot(event model.Event, clock *utils.Clock) (model.Payload, error) {
+	target, err := strconv.Atoi(event.ExtraParams)
+	if err != nil || target < 1 || target > model.TargetsPerLine {
+		return nil, fmt.Errorf("%w: target %q is not within 1..%d",
+			utils.ErrInvalidEventParams, event.ExtraParams, model.TargetsPerLine)
+	}
+	return model.ShotPayload{Target: target}, nil
+}
+
+func decodeComment(event model.Event, clock *utils.Clock) (model.Payload, error) {
+	return model.CommentPayload{Text: event.ExtraParams}, nil
+}
+
+// withPayload decodes the payload of an event built without one, such as an
+// event constructed directly rather than read by a parser.
+func withPayload(event model.Event, clock *utils.Clock) (model.Event, error) {
+	if event.Payload != nil {
+		return event, nil
+	}
+
+	payload, err := decodePayload(event, clock)
+	if err != nil {
+		return event, err
+	}
+	event.Payload = payload
+	return event, nil
+}
+
+// paramsColumn is the 1-based column of params within line, or 1 when it
+// cannot be located.
+func paramsColumn(line, params string) int {
+	if i := strings.LastIndex(line, params); params != "" && i >= 0 {
+		return i + 1
+	}
+	return 1
+}
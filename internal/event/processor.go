@@ -2,62 +2,60 @@ package event
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"sort"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/niklvdanya/BiathlonTracker/internal/config"
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
-func ProcessEvents(ctx context.Context, events []model.Event, cfg config.Config) map[int]*model.Competitor {
+func ProcessEvents(ctx context.Context, input []model.Event, cfg config.Config) model.ProcessResult {
 	competitors := make(map[int]*model.Competitor)
 
-	_, startDeltaDuration := calculateTimingParameters(cfg)
+	timing := calculateTimingParameters(cfg)
 
-	sortEvents(events)
-	processedEvents := make([]model.Event, 0, len(events))
+	events := sortEvents(input)
+	entries := make([]logEntry, 0, len(events))
 
 	for i := range events {
 		select {
 		case <-ctx.Done():
-			return competitors
+			return buildResult(competitors, entries)
 		default:
-			event := events[i]
-			if event.Processed {
-				continue
-			}
-			event.Processed = true
-
-			competitor := getOrCreateCompetitor(competitors, event.CompetitorID, cfg.Laps)
-			processEvent(competitor, event, cfg, startDeltaDuration, &processedEvents)
-			processedEvents = append(processedEvents, event)
+			competitor := getOrCreateCompetitor(competitors, events[i].CompetitorID, cfg.Laps)
+			processLoggedEvent(competitor, i, events[i], cfg, timing, &entries)
 		}
 	}
 
-	for i := range events {
-		events[i] = processedEvents[i]
+	lastEventTime := getLastEventTime(events)
+	for _, competitorID := range sortedCompetitorIDs(competitors) {
+		finishCompetitor(competitors[competitorID], len(events), lastEventTime, timing, &entries)
 	}
 
-	return competitors
+	return buildResult(competitors, entries)
 }
 
-func ProcessEventsParallel(ctx context.Context, events []model.Event, cfg config.Config) map[int]*model.Competitor {
-	sortEvents(events)
+func ProcessEventsParallel(ctx context.Context, input []model.Event, cfg config.Config) model.ProcessResult {
+	events := sortEvents(input)
+	lastEventTime := getLastEventTime(events)
 
-	competitorEvents := make(map[int][]model.Event)
-	for _, event := range events {
-		competitorEvents[event.CompetitorID] = append(competitorEvents[event.CompetitorID], event)
+	competitorEvents := make(map[int][]int)
+	for i, event := range events {
+		competitorEvents[event.CompetitorID] = append(competitorEvents[event.CompetitorID], i)
 	}
 
 	competitors := make(map[int]*model.Competitor)
+	competitorEntries := make(map[int][]logEntry)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
-	for competitorID, events := range competitorEvents {
+	for competitorID, indices := range competitorEvents {
 		wg.Add(1)
-		go func(cID int, evts []model.Event) {
+		go func(cID int, idx []int) {
 			defer wg.Done()
 
 			competitor := &model.Competitor{
@@ -67,30 +65,113 @@ func ProcessEventsParallel(ctx context.Context, events []model.Event, cfg config
 				CurrentLap: 1,
 			}
 
-			processCompetitorEvents(competitor, evts, cfg)
+			entries := processCompetitorEvents(ctx, competitor, events, idx, cfg, lastEventTime)
 
 			mu.Lock()
 			competitors[cID] = competitor
+			competitorEntries[cID] = entries
 			mu.Unlock()
-		}(competitorID, events)
+		}(competitorID, indices)
 	}
 
 	wg.Wait()
-	return competitors
+
+	var entries []logEntry
+	for _, competitorID := range sortedCompetitorIDs(competitors) {
+		entries = append(entries, competitorEntries[competitorID]...)
+	}
+
+	return buildResult(competitors, entries)
 }
 
-func calculateTimingParameters(cfg config.Config) (time.Time, time.Duration) {
-	baseTime, _ := time.Parse(model.TimeFormat, model.ZeroTimeString)
-	startDelta, _ := time.Parse(model.TimeFormat, cfg.StartDelta)
-	startDeltaDuration := startDelta.Sub(baseTime)
+// logEntry tags an output log event with the index of the input event that
+// produced it, so that logs built per competitor merge into exactly the
+// order of a sequential run.
+type logEntry struct {
+	seq       int
+	generated bool
+	event     model.Event
+}
 
-	return baseTime, startDeltaDuration
+func buildResult(competitors map[int]*model.Competitor, entries []logEntry) model.ProcessResult {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].event.Time.Equal(entries[j].event.Time) {
+			return entries[i].event.Time.Before(entries[j].event.Time)
+		}
+		return entries[i].seq < entries[j].seq
+	})
+
+	result := model.ProcessResult{
+		Competitors: competitors,
+		OutputLog:   make([]model.Event, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		result.OutputLog = append(result.OutputLog, entry.event)
+		if entry.generated {
+			result.Stats.GeneratedEvents++
+		} else {
+			result.Stats.ProcessedEvents++
+		}
+	}
+
+	for _, competitorID := range sortedCompetitorIDs(competitors) {
+		result.Anomalies = append(result.Anomalies, competitors[competitorID].Anomalies...)
+	}
+	sort.SliceStable(result.Anomalies, func(i, j int) bool {
+		return result.Anomalies[i].Time.Before(result.Anomalies[j].Time)
+	})
+
+	result.Stats.Competitors = len(competitors)
+	return result
+}
+
+type raceTiming struct {
+	clock      *utils.Clock
+	start      time.Time
+	startDelta time.Duration
+}
+
+// calculateTimingParameters relies on config.Load having validated the
+// config, so only an unset start or startDelta yields a zero value.
+func calculateTimingParameters(cfg config.Config) raceTiming {
+	clock, err := cfg.Clock()
+	if err != nil {
+		clock = utils.NewClock(time.Now())
+	}
+	start, _ := clock.Parse(cfg.Start)
+	startDelta, _ := cfg.StartDeltaDuration()
+
+	return raceTiming{
+		clock:      clock,
+		start:      start,
+		startDelta: startDelta,
+	}
 }
 
-func sortEvents(events []model.Event) {
-	sort.Slice(events, func(i, j int) bool {
+// sortEvents returns a time-ordered copy, leaving the caller's slice as is.
+func sortEvents(input []model.Event) []model.Event {
+	events := slices.Clone(input)
+	sort.SliceStable(events, func(i, j int) bool {
 		return events[i].Time.Before(events[j].Time)
 	})
+	return events
+}
+
+func getLastEventTime(events []model.Event) time.Time {
+	if len(events) == 0 {
+		return time.Time{}
+	}
+	return events[len(events)-1].Time
+}
+
+func sortedCompetitorIDs(competitors map[int]*model.Competitor) []int {
+	ids := make([]int, 0, len(competitors))
+	for id := range competitors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
 }
 
 func getOrCreateCompetitor(competitors map[int]*model.Competitor, competitorID int, laps int) *model.Competitor {
@@ -107,48 +188,104 @@ func getOrCreateCompetitor(competitors map[int]*model.Competitor, competitorID i
 	return competitor
 }
 
-func processCompetitorEvents(competitor *model.Competitor, events []model.Event, cfg config.Config) {
-	baseTime, _ := time.Parse(model.TimeFormat, model.ZeroTimeString)
-	startDelta, _ := time.Parse(model.TimeFormat, cfg.StartDelta)
-	startDeltaDuration := startDelta.Sub(baseTime)
-
-	processedEvents := make([]model.Event, 0, len(events))
+func processCompetitorEvents(ctx context.Context, competitor *model.Competitor, events []model.Event, indices []int, cfg config.Config, lastEventTime time.Time) []logEntry {
+	timing := calculateTimingParameters(cfg)
+	entries := make([]logEntry, 0, len(indices))
 
-	for _, event := range events {
-		if event.Processed {
-			continue
+	for _, i := range indices {
+		if ctx.Err() != nil {
+			return entries
 		}
-		event.Processed = true
+		processLoggedEvent(competitor, i, events[i], cfg, timing, &entries)
+	}
+
+	finishCompetitor(competitor, len(events), lastEventTime, timing, &entries)
+	return entries
+}
+
+// processLoggedEvent applies one input event and logs it together with the
+// outgoing events it generated.
+func processLoggedEvent(competitor *model.Competitor, seq int, event model.Event, cfg config.Config, timing raceTiming, entries *[]logEntry) {
+	if event.Processed {
+		return
+	}
+	event.Processed = true
+
+	var processedEvents []model.Event
+	processEvent(competitor, event, cfg, timing, &processedEvents)
+
+	for _, processed := range processedEvents {
+		*entries = append(*entries, logEntry{seq: seq, generated: true, event: processed})
+	}
+	*entries = append(*entries, logEntry{seq: seq, event: event})
+}
+
+// finishCompetitor runs the checks that need the whole event stream; the
+// events it generates are ordered after every input event.
+func finishCompetitor(competitor *model.Competitor, seq int, lastEventTime time.Time, timing raceTiming, entries *[]logEntry) {
+	var processedEvents []model.Event
+	checkMissedStart(competitor, lastEventTime, timing.startDelta, &processedEvents)
+
+	for _, processed := range processedEvents {
+		*entries = append(*entries, logEntry{seq: seq, generated: true, event: processed})
+	}
+}
+
+// checkMissedStart runs once all events are processed. A competitor whose
+// start window closed before the last observed event without a start is
+// reported as not started; one without a drawn start time is an anomaly.
+func checkMissedStart(competitor *model.Competitor, lastEventTime time.Time, startDeltaDuration time.Duration, processedEvents *[]model.Event) {
+	if competitor.PlannedStart.IsZero() {
+		// Not caused by any one event, so not a ProcessingError.
+		flagAnomaly(competitor, lastEventTime, fmt.Errorf("competitor %d: %w", competitor.ID, utils.ErrNoStartDrawn))
+		return
+	}
 
-		processEvent(competitor, event, cfg, startDeltaDuration, &processedEvents)
-		processedEvents = append(processedEvents, event)
+	if competitor.Status != model.StatusNotStarted || !competitor.ActualStart.IsZero() {
+		return
+	}
+
+	startDeadline := competitor.PlannedStart.Add(startDeltaDuration)
+	if startDeadline.Before(lastEventTime) {
+		setState(competitor, model.StateNotStarted)
+		notStartedEvent := model.Event{
+			Time:         startDeadline,
+			EventID:      model.EventNotStarted,
+			CompetitorID: competitor.ID,
+			Processed:    true,
+		}
+		*processedEvents = append(*processedEvents, notStartedEvent)
 	}
 }
 
-func processEvent(competitor *model.Competitor, event model.Event, cfg config.Config, startDeltaDuration time.Duration, processedEvents *[]model.Event) {
-	switch event.EventID {
-	case model.EventRegistration:
-		handleRegistrationEvent(competitor, event)
-	case model.EventSetStartTime:
-		handleSetStartTimeEvent(competitor, event)
-	case model.EventStartLine:
-		// start line
-	case model.EventStarted:
-		handleStartedEvent(competitor, event, startDeltaDuration, processedEvents)
-	case model.EventFiringRange:
-		handleFiringRangeEvent(competitor, event)
-	case model.EventShot:
-		handleShotEvent(competitor, event)
-	case model.EventLeaveFiring:
-		handleLeaveFireEvent(competitor, event)
-	case model.EventEnterPenalty:
-		handleEnterPenaltyEvent(competitor, event)
-	case model.EventLeavePenalty:
-		handleLeavePenaltyEvent(competitor, event, cfg)
-	case model.EventLapEnd:
-		handleLapEndEvent(competitor, event, cfg, processedEvents)
-	case model.EventLostInForest:
-		handleLostEvent(competitor, event)
+func processEvent(competitor *model.Competitor, event model.Event, cfg config.Config, timing raceTiming, processedEvents *[]model.Event) {
+	eventType, known := LookupEventType(event.EventID)
+	if !known {
+		addAnomaly(competitor, event, "unknown event")
+		return
+	}
+	if eventType.Generated {
+		addAnomaly(competitor, event, "event is only generated by the processor")
+		return
+	}
+
+	event, err := withPayload(event, timing.clock)
+	if err != nil {
+		addAnomaly(competitor, event, err.Error())
+		return
+	}
+
+	if err := transition(competitor, event); err != nil {
+		flagAnomaly(competitor, event.Time, err)
+		return
+	}
+
+	if eventType.Handle != nil {
+		eventType.Handle(competitor, event, &HandlerContext{
+			Config:          cfg,
+			timing:          timing,
+			processedEvents: processedEvents,
+		})
 	}
 }
 
@@ -156,19 +293,33 @@ func handleRegistrationEvent(competitor *model.Competitor, event model.Event) {
 	competitor.RegisteredTime = event.Time
 }
 
-func handleSetStartTimeEvent(competitor *model.Competitor, event model.Event) {
-	startTime, err := time.Parse(model.TimeFormat, event.ExtraParams)
-	if err == nil {
-		competitor.PlannedStart = startTime
+func handleSetStartTimeEvent(competitor *model.Competitor, event model.Event, timing raceTiming) {
+	payload, ok := event.Payload.(model.StartTimePayload)
+	if !ok {
+		return
 	}
+
+	startTime := payload.Time
+	competitor.PlannedStart = startTime
+	if !timing.start.IsZero() && startTime.Before(timing.start) {
+		addAnomaly(competitor, event, fmt.Sprintf("drawn start time %s is before the competition start %s",
+			utils.FormatTimeRFC(startTime), utils.FormatTimeRFC(timing.start)))
+	}
+}
+
+func handleStartLineEvent(competitor *model.Competitor, event model.Event) {
+	competitor.StartLineTime = event.Time
 }
 
 func handleStartedEvent(competitor *model.Competitor, event model.Event, startDeltaDuration time.Duration, processedEvents *[]model.Event) {
 	competitor.ActualStart = event.Time
-	competitor.Status = model.StatusRunning
+
+	if competitor.StartLineTime.IsZero() {
+		addAnomaly(competitor, event, "started without being on the start line")
+	}
 
 	if event.Time.Sub(competitor.PlannedStart) > startDeltaDuration {
-		competitor.Status = model.StatusDisqualified
+		setState(competitor, model.StateDisqualified)
 		disqEvent := model.Event{
 			Time:         event.Time,
 			EventID:      model.EventDisqualified,
@@ -179,62 +330,197 @@ func handleStartedEvent(competitor *model.Competitor, event model.Event, startDe
 	}
 }
 
-func handleFiringRangeEvent(competitor *model.Competitor, event model.Event) {
-	competitor.OnFiringRange = true
-	firingRange, _ := strconv.Atoi(event.ExtraParams)
+func handleFiringRangeEvent(competitor *model.Competitor, event model.Event, cfg config.Config) {
+	payload, ok := event.Payload.(model.FiringRangePayload)
+	if !ok {
+		return
+	}
+
+	firingRange := payload.Line
+	if cfg.FiringLines > 0 && firingRange > cfg.FiringLines {
+		// Shots on a line that does not exist count towards nothing.
+		addAnomaly(competitor, event, fmt.Sprintf("firing line %d is not within 1..%d, visit not recorded", firingRange, cfg.FiringLines))
+		competitor.CurrentFiring = 0
+		return
+	}
+	if cfg.FiringLines > 0 {
+		checkFiringRange(competitor, event, firingRange, cfg.FiringLines)
+	}
+
 	competitor.CurrentFiring = firingRange
+	competitor.Firings = append(competitor.Firings, model.FiringRecord{
+		Line:      firingRange,
+		Misses:    model.TargetsPerLine,
+		EnterTime: event.Time,
+	})
+}
+
+// checkFiringRange expects the firing lines of each lap to be visited once,
+// in order, starting from line 1.
+func checkFiringRange(competitor *model.Competitor, event model.Event, firingRange, firingLines int) {
+	visit := len(firingsSince(competitor, currentLapStart(competitor))) + 1
+	switch {
+	case visit > firingLines:
+		addAnomaly(competitor, event, fmt.Sprintf("firing range visit %d on lap %d, only %d firing lines per lap",
+			visit, competitor.CurrentLap, firingLines))
+	case firingRange != visit:
+		addAnomaly(competitor, event, fmt.Sprintf("firing line %d visited out of order on lap %d, expected %d",
+			firingRange, competitor.CurrentLap, visit))
+	}
+}
+
+func currentLapStart(competitor *model.Competitor) time.Time {
+	if competitor.CurrentLap > 1 {
+		return competitor.LapTimes[competitor.CurrentLap-2].Finish
+	}
+	return competitor.ActualStart
+}
+
+// currentFiring is the record of the latest firing range visit, or nil when
+// that visit was not recorded.
+func currentFiring(competitor *model.Competitor) *model.FiringRecord {
+	if competitor.CurrentFiring == 0 {
+		return nil
+	}
+	return competitor.LastFiring()
 }
 
+func firingsSince(competitor *model.Competitor, since time.Time) []model.FiringRecord {
+	for i, firing := range competitor.Firings {
+		if !firing.EnterTime.Before(since) {
+			return competitor.Firings[i:]
+		}
+	}
+	return nil
+}
+
+// handleShotEvent records a hit target. Misses are never reported directly:
+// every target that is not hit by the time the competitor leaves the range
+// counts as a miss.
 func handleShotEvent(competitor *model.Competitor, event model.Event) {
-	if event.ExtraParams == model.ShotTarget3 {
-		competitor.MissedShot = true
-		competitor.TotalShots++
-	} else {
-		competitor.ShotsHit++
-		competitor.TotalShots++
+	firing := currentFiring(competitor)
+	payload, ok := event.Payload.(model.ShotPayload)
+	if firing == nil || !ok {
+		return
 	}
+
+	target := payload.Target
+
+	if slices.Contains(firing.TargetsHit, target) {
+		addAnomaly(competitor, event, fmt.Sprintf("target %d hit more than once on firing line %d", target, firing.Line))
+		return
+	}
+
+	firing.TargetsHit = append(firing.TargetsHit, target)
+	firing.Misses--
 }
 
-func handleLeaveFireEvent(competitor *model.Competitor, _ model.Event) {
-	competitor.OnFiringRange = false
+func handleLeaveFireEvent(competitor *model.Competitor, event model.Event) {
+	if firing := currentFiring(competitor); firing != nil {
+		firing.LeaveTime = event.Time
+	}
 }
 
+// handleEnterPenaltyEvent owes the loops for the misses of the latest visit
+// that earlier stints have not already covered, so a penalty may be served
+// in several stints.
 func handleEnterPenaltyEvent(competitor *model.Competitor, event model.Event) {
-	competitor.InPenalty = true
-	competitor.PenaltyLapInfo.StartTime = event.Time
+	loops := 0
+	if firing := currentFiring(competitor); firing != nil {
+		loops = max(firing.Misses-firing.PenaltyLoops, 0)
+	}
+
+	competitor.Penalties = append(competitor.Penalties, model.PenaltyInfo{
+		StartTime: event.Time,
+		Loops:     loops,
+	})
 }
 
-func handleLeavePenaltyEvent(competitor *model.Competitor, event model.Event, cfg config.Config) {
-	competitor.InPenalty = false
-	if competitor.IsRunning() {
-		penaltyDuration := event.Time.Sub(competitor.PenaltyLapInfo.StartTime)
-		speed := float64(cfg.PenaltyLen) / penaltyDuration.Seconds()
-		competitor.PenaltyLapInfo.Duration = penaltyDuration
-		competitor.PenaltyLapInfo.Speed = speed
+func handleLeavePenaltyEvent(competitor *model.Competitor, event model.Event, cfg config.Config, processedEvents *[]model.Event) {
+	penalty := competitor.LastPenalty()
+	if penalty != nil && competitor.IsRunning() {
+		penaltyDuration := event.Time.Sub(penalty.StartTime)
+		penalty.Duration = penaltyDuration
+		if penaltyDuration > 0 {
+			penalty.Speed = float64(cfg.PenaltyLen*penalty.Loops) / penaltyDuration.Seconds()
+		}
+
+		penalty.Completed = estimatePenaltyLoops(penaltyDuration, cfg)
+		if firing := currentFiring(competitor); firing != nil {
+			firing.PenaltyLoops += penalty.Completed
+		}
+		if penalty.Completed < penalty.Loops {
+			reportPenaltyShortfall(competitor, event, penalty.Completed, penalty.Loops, processedEvents)
+		}
+	}
+}
+
+// estimatePenaltyLoops returns the most loops that could have been skied in
+// the given time without exceeding the configured penalty speed limit.
+func estimatePenaltyLoops(penaltyDuration time.Duration, cfg config.Config) int {
+	if cfg.PenaltyLen <= 0 {
+		return 0
+	}
+	return int(penaltyDuration.Seconds() * cfg.PenaltySpeedLimit() / float64(cfg.PenaltyLen))
+}
+
+// checkSkippedPenalties flags firing visits of the lap started at lapStart
+// that had misses but were never followed by a penalty stint.
+func checkSkippedPenalties(competitor *model.Competitor, event model.Event, lapStart time.Time, processedEvents *[]model.Event) {
+	for i, firing := range competitor.Firings {
+		if firing.EnterTime.Before(lapStart) || firing.Misses == 0 {
+			continue
+		}
+
+		nextFiring := event.Time
+		if i+1 < len(competitor.Firings) {
+			nextFiring = competitor.Firings[i+1].EnterTime
+		}
+
+		served := slices.ContainsFunc(competitor.Penalties, func(penalty model.PenaltyInfo) bool {
+			return !penalty.StartTime.Before(firing.LeaveTime) && penalty.StartTime.Before(nextFiring)
+		})
+		if !served {
+			reportPenaltyShortfall(competitor, event, 0, firing.Misses, processedEvents)
+		}
+	}
+}
+
+func reportPenaltyShortfall(competitor *model.Competitor, event model.Event, completed, owed int, processedEvents *[]model.Event) {
+	competitor.PenaltyShortfall = true
+	shortfallEvent := model.Event{
+		Time:         event.Time,
+		EventID:      model.EventPenaltyShortfall,
+		CompetitorID: competitor.ID,
+		ExtraParams:  fmt.Sprintf("%d/%d", completed, owed),
+		Processed:    true,
 	}
+	*processedEvents = append(*processedEvents, shortfallEvent)
 }
 
 func handleLapEndEvent(competitor *model.Competitor, event model.Event, cfg config.Config, processedEvents *[]model.Event) {
 	if competitor.IsRunning() {
-		var lapTime time.Duration
-		if competitor.CurrentLap == 1 {
-			lapTime = event.Time.Sub(competitor.ActualStart)
-		} else {
-			previousFinish := competitor.LapTimes[competitor.CurrentLap-2].Finish
-			lapTime = event.Time.Sub(previousFinish)
+		lapStart := currentLapStart(competitor)
+		lapTime := event.Time.Sub(lapStart)
+
+		checkSkippedPenalties(competitor, event, lapStart, processedEvents)
+
+		if visits := len(firingsSince(competitor, lapStart)); cfg.FiringLines > 0 && visits != cfg.FiringLines {
+			addAnomaly(competitor, event, fmt.Sprintf("lap %d ended after %d firing range visits, expected %d",
+				competitor.CurrentLap, visits, cfg.FiringLines))
 		}
 
 		speed := float64(cfg.LapLen) / lapTime.Seconds()
-		competitor.LapTimes[competitor.CurrentLap-1] = model.LapInfo{
-			Time:   lapTime,
-			Speed:  speed,
-			Finish: event.Time,
-		}
+		lap := lapSplits(competitor, lapStart, event.Time)
+		lap.Time = lapTime
+		lap.Speed = speed
+		lap.Finish = event.Time
+		competitor.LapTimes[competitor.CurrentLap-1] = lap
 
 		competitor.CurrentLap++
 
 		if competitor.CurrentLap > cfg.Laps {
-			competitor.Status = model.StatusFinished
+			setState(competitor, model.StateFinished)
 			finishEvent := model.Event{
 				Time:         event.Time,
 				EventID:      model.EventFinished,
@@ -246,7 +532,34 @@ func handleLapEndEvent(competitor *model.Competitor, event model.Event, cfg conf
 	}
 }
 
+func lapSplits(competitor *model.Competitor, lapStart, lapEnd time.Time) model.LapInfo {
+	var lap model.LapInfo
+
+	firings := firingsSince(competitor, lapStart)
+	if len(firings) > 0 {
+		lap.ToRange = firings[0].EnterTime.Sub(lapStart)
+	}
+	for _, firing := range firings {
+		if !firing.LeaveTime.IsZero() {
+			lap.RangeTime += firing.LeaveTime.Sub(firing.EnterTime)
+		}
+	}
+
+	for _, penalty := range competitor.Penalties {
+		if !penalty.StartTime.Before(lapStart) && penalty.StartTime.Before(lapEnd) {
+			lap.PenaltyTime += penalty.Duration
+		}
+	}
+
+	lap.SkiTime = lapEnd.Sub(lapStart) - lap.RangeTime - lap.PenaltyTime
+	return lap
+}
+
 func handleLostEvent(competitor *model.Competitor, event model.Event) {
-	competitor.Status = model.StatusNotFinished
-	competitor.StatusComment = event.ExtraParams
+	if !competitor.ActualStart.IsZero() && competitor.CurrentLap <= len(competitor.LapTimes) {
+		competitor.LapTimes[competitor.CurrentLap-1].Elapsed = event.Time.Sub(currentLapStart(competitor))
+	}
+	if payload, ok := event.Payload.(model.CommentPayload); ok {
+		competitor.StatusComment = payload.Text
+	}
 }
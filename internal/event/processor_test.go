@@ -2,13 +2,30 @@ package event
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/niklvdanya/BiathlonTracker/internal/config"
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
+// startEvents registers a competitor, draws the given start time and starts
+// them on it.
+func startEvents(competitorID int, start time.Time) []model.Event {
+	return []model.Event{
+		{Time: start.Add(-time.Hour), EventID: model.EventRegistration, CompetitorID: competitorID},
+		{Time: start.Add(-30 * time.Minute), EventID: model.EventSetStartTime, CompetitorID: competitorID, ExtraParams: utils.FormatTimeRFC(start)},
+		{Time: start.Add(-time.Minute), EventID: model.EventStartLine, CompetitorID: competitorID},
+		{Time: start, EventID: model.EventStarted, CompetitorID: competitorID},
+	}
+}
+
 func TestRegistrationEvent(t *testing.T) {
 	ctx := context.Background()
 
@@ -21,6 +38,7 @@ func TestRegistrationEvent(t *testing.T) {
 		FiringLines: 2,
 		Start:       "10:00:00.000",
 		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
 	}
 
 	events := []model.Event{
@@ -32,7 +50,7 @@ func TestRegistrationEvent(t *testing.T) {
 	}
 
 	processor := &DefaultEventProcessor{}
-	competitors := processor.Process(ctx, events, cfg)
+	competitors := processor.Process(ctx, events, cfg).Competitors
 
 	competitor, exists := competitors[1]
 	if !exists {
@@ -52,6 +70,7 @@ func TestLostInForestEvent(t *testing.T) {
 	ctx := context.Background()
 
 	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lostInForest := "Lost in the forest"
 
 	cfg := config.Config{
 		Laps:        2,
@@ -60,6 +79,7 @@ func TestLostInForestEvent(t *testing.T) {
 		FiringLines: 2,
 		Start:       "10:00:00.000",
 		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
 	}
 
 	events := []model.Event{
@@ -67,12 +87,12 @@ func TestLostInForestEvent(t *testing.T) {
 			Time:         baseTime.Add(10 * time.Hour),
 			EventID:      model.EventLostInForest,
 			CompetitorID: 1,
-			ExtraParams:  model.LostInForestText,
+			ExtraParams:  lostInForest,
 		},
 	}
 
 	processor := &DefaultEventProcessor{}
-	competitors := processor.Process(ctx, events, cfg)
+	competitors := processor.Process(ctx, events, cfg).Competitors
 
 	competitor, exists := competitors[1]
 	if !exists {
@@ -83,8 +103,8 @@ func TestLostInForestEvent(t *testing.T) {
 		t.Errorf("expected status %s, got %s", model.StatusNotFinished, competitor.Status)
 	}
 
-	if competitor.StatusComment != model.LostInForestText {
-		t.Errorf("expected status comment %s, got %s", model.LostInForestText, competitor.StatusComment)
+	if competitor.StatusComment != lostInForest {
+		t.Errorf("expected status comment %s, got %s", lostInForest, competitor.StatusComment)
 	}
 }
 
@@ -100,6 +120,7 @@ func TestDisqualificationEvent(t *testing.T) {
 		FiringLines: 2,
 		Start:       "10:00:00.000",
 		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
 	}
 
 	events := []model.Event{
@@ -122,7 +143,7 @@ func TestDisqualificationEvent(t *testing.T) {
 	}
 
 	processor := &DefaultEventProcessor{}
-	competitors := processor.Process(ctx, events, cfg)
+	competitors := processor.Process(ctx, events, cfg).Competitors
 
 	competitor, exists := competitors[1]
 	if !exists {
@@ -134,6 +155,138 @@ func TestDisqualificationEvent(t *testing.T) {
 	}
 }
 
+func TestStartWithinDelta(t *testing.T) {
+	ctx := context.Background()
+
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	events := []model.Event{
+		{
+			Time:         baseTime.Add(9*time.Hour + 5*time.Minute),
+			EventID:      model.EventRegistration,
+			CompetitorID: 1,
+		},
+		{
+			Time:         baseTime.Add(9*time.Hour + 15*time.Minute),
+			EventID:      model.EventSetStartTime,
+			CompetitorID: 1,
+			ExtraParams:  "10:00:00.000",
+		},
+		{
+			Time:         baseTime.Add(10*time.Hour + time.Minute),
+			EventID:      model.EventStarted,
+			CompetitorID: 1,
+		},
+	}
+
+	processor := &DefaultEventProcessor{}
+	competitors := processor.Process(ctx, events, cfg).Competitors
+
+	competitor := competitors[1]
+	if !competitor.PlannedStart.Equal(baseTime.Add(10 * time.Hour)) {
+		t.Errorf("expected planned start %v, got %v", baseTime.Add(10*time.Hour), competitor.PlannedStart)
+	}
+
+	if competitor.Status != model.StatusRunning {
+		t.Errorf("expected status %s, got %s", model.StatusRunning, competitor.Status)
+	}
+}
+
+func TestCompetitionAcrossMidnight(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.Config{
+		Laps:        1,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       "23:30:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	input := `[23:50:00.000] 1 1
+[23:55:00.000] 2 1 00:05:00.000
+[00:05:10.000] 4 1
+[00:20:10.000] 10 1
+`
+
+	events, _, err := ReadEvents(strings.NewReader(input), ParseOptions{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+
+	processor := &DefaultEventProcessor{}
+	competitors := processor.Process(ctx, events, cfg).Competitors
+
+	competitor := competitors[1]
+	if competitor.Status != model.StatusFinished {
+		t.Fatalf("expected status %s, got %s", model.StatusFinished, competitor.Status)
+	}
+
+	if competitor.TotalTime() != 15*time.Minute {
+		t.Errorf("expected total time 15m, got %v", competitor.TotalTime())
+	}
+}
+
+func TestStartLine(t *testing.T) {
+	ctx := context.Background()
+
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	events := []model.Event{
+		{Time: baseTime.Add(9 * time.Hour), EventID: model.EventRegistration, CompetitorID: 1},
+		{Time: baseTime.Add(9 * time.Hour), EventID: model.EventRegistration, CompetitorID: 2},
+		{Time: baseTime.Add(9*time.Hour + 30*time.Minute), EventID: model.EventSetStartTime, CompetitorID: 1, ExtraParams: "10:00:00.000"},
+		{Time: baseTime.Add(9*time.Hour + 30*time.Minute), EventID: model.EventSetStartTime, CompetitorID: 2, ExtraParams: "09:59:00.000"},
+		{Time: baseTime.Add(9*time.Hour + 59*time.Minute + 40*time.Second), EventID: model.EventStartLine, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Hour + 5*time.Second), EventID: model.EventStarted, CompetitorID: 1},
+		{Time: baseTime.Add(10 * time.Hour), EventID: model.EventStarted, CompetitorID: 2},
+	}
+
+	processor := &DefaultEventProcessor{}
+	result := processor.Process(ctx, events, cfg)
+
+	onStartLine := result.Competitors[1]
+	if !onStartLine.StartLineTime.Equal(events[4].Time) {
+		t.Errorf("expected start line time %v, got %v", events[4].Time, onStartLine.StartLineTime)
+	}
+	if onStartLine.TimeOnStartLine() != 25*time.Second {
+		t.Errorf("expected 25s on the start line, got %v", onStartLine.TimeOnStartLine())
+	}
+	if len(onStartLine.Anomalies) != 0 {
+		t.Errorf("competitor 1: expected no anomalies, got %v", onStartLine.Anomalies)
+	}
+
+	skipped := result.Competitors[2]
+	if len(skipped.Anomalies) != 2 {
+		t.Fatalf("competitor 2: expected early draw and missing start line anomalies, got %v", skipped.Anomalies)
+	}
+	if !skipped.Anomalies[0].Time.Equal(events[3].Time) || !skipped.Anomalies[1].Time.Equal(events[6].Time) {
+		t.Errorf("competitor 2: unexpected anomalies %v", skipped.Anomalies)
+	}
+}
+
 func TestShotEvent(t *testing.T) {
 	ctx := context.Background()
 
@@ -146,6 +299,7 @@ func TestShotEvent(t *testing.T) {
 		FiringLines: 2,
 		Start:       "10:00:00.000",
 		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
 	}
 
 	events := []model.Event{
@@ -159,34 +313,221 @@ func TestShotEvent(t *testing.T) {
 			Time:         baseTime.Add(10*time.Hour + time.Second),
 			EventID:      model.EventShot,
 			CompetitorID: 1,
-			ExtraParams:  model.ShotTarget1,
+			ExtraParams:  "1",
 		},
 		{
 			Time:         baseTime.Add(10*time.Hour + 2*time.Second),
 			EventID:      model.EventShot,
 			CompetitorID: 1,
-			ExtraParams:  model.ShotTarget3,
+			ExtraParams:  "3",
 		},
 	}
 
 	processor := &DefaultEventProcessor{}
-	competitors := processor.Process(ctx, events, cfg)
+	competitors := processor.Process(ctx, append(startEvents(1, baseTime.Add(9*time.Hour+30*time.Minute)), events...), cfg).Competitors
 
 	competitor, exists := competitors[1]
 	if !exists {
 		t.Fatalf("competitor 1 not found")
 	}
 
-	if competitor.ShotsHit != 1 {
-		t.Errorf("expected 1 shot hit, got %d", competitor.ShotsHit)
+	if competitor.ShotsHit() != 2 {
+		t.Errorf("expected 2 shots hit, got %d", competitor.ShotsHit())
+	}
+
+	if competitor.TotalShots() != 5 {
+		t.Errorf("expected 5 total shots, got %d", competitor.TotalShots())
+	}
+
+	if len(competitor.Firings) != 1 || competitor.Firings[0].Misses != 3 {
+		t.Errorf("expected one firing record with 3 misses, got %+v", competitor.Firings)
 	}
+}
+
+func TestFiringRecords(t *testing.T) {
+	ctx := context.Background()
+
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	if competitor.TotalShots != 2 {
-		t.Errorf("expected 2 total shots, got %d", competitor.TotalShots)
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	events := []model.Event{
+		{Time: baseTime.Add(10 * time.Hour), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Hour + 1*time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Hour + 2*time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "2"},
+		{Time: baseTime.Add(10*time.Hour + 3*time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "2"},
+		{Time: baseTime.Add(10*time.Hour + 4*time.Second), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Hour + 20*time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "2"},
+		{Time: baseTime.Add(10*time.Hour + 20*time.Minute + time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "5"},
+		{Time: baseTime.Add(10*time.Hour + 20*time.Minute + 2*time.Second), EventID: model.EventLeaveFiring, CompetitorID: 1},
+	}
+
+	processor := &DefaultEventProcessor{}
+	competitors := processor.Process(ctx, append(startEvents(1, baseTime.Add(9*time.Hour+30*time.Minute)), events...), cfg).Competitors
+
+	competitor := competitors[1]
+	if len(competitor.Firings) != 2 {
+		t.Fatalf("expected 2 firing records, got %d", len(competitor.Firings))
+	}
+
+	first := competitor.Firings[0]
+	if first.Line != 1 || first.Misses != 3 || len(first.TargetsHit) != 2 {
+		t.Errorf("unexpected first firing record: %+v", first)
+	}
+	if !first.EnterTime.Equal(events[0].Time) || !first.LeaveTime.Equal(events[4].Time) {
+		t.Errorf("unexpected first firing record times: %v - %v", first.EnterTime, first.LeaveTime)
 	}
 
-	if !competitor.MissedShot {
-		t.Errorf("expected missed shot flag to be true")
+	second := competitor.Firings[1]
+	if second.Line != 2 || second.Misses != 4 {
+		t.Errorf("unexpected second firing record: %+v", second)
+	}
+
+	if competitor.ShotAccuracy() != "3/10" {
+		t.Errorf("expected accuracy 3/10, got %s", competitor.ShotAccuracy())
+	}
+
+	// The draw at 09:30 precedes the configured 10:00 start.
+	drawTime := baseTime.Add(9 * time.Hour)
+	if len(competitor.Anomalies) != 2 ||
+		!competitor.Anomalies[0].Time.Equal(drawTime) || !strings.Contains(competitor.Anomalies[0].Err.Error(), "before the competition start") ||
+		!competitor.Anomalies[1].Time.Equal(events[3].Time) || !strings.Contains(competitor.Anomalies[1].Err.Error(), "hit more than once") {
+		t.Errorf("expected an early draw and a duplicate hit anomaly, got %v", competitor.Anomalies)
+	}
+}
+
+func TestIllegalTransitions(t *testing.T) {
+	ctx := context.Background()
+
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	events := []model.Event{
+		{Time: baseTime.Add(10*time.Hour + 10*time.Minute), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Hour + 11*time.Minute), EventID: model.EventEnterPenalty, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Hour + 12*time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Hour + 13*time.Minute), EventID: model.EventLeavePenalty, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Hour + 14*time.Minute), EventID: model.EventLeavePenalty, CompetitorID: 1},
+	}
+
+	processor := &DefaultEventProcessor{}
+	result := processor.Process(ctx, append(startEvents(1, baseTime.Add(10*time.Hour)), events...), cfg)
+
+	competitor := result.Competitors[1]
+	if competitor.State != model.StateRunning {
+		t.Errorf("expected state %s, got %s", model.StateRunning, competitor.State)
+	}
+
+	if competitor.TotalShots() != 0 {
+		t.Errorf("expected the shot without a firing range to be rejected, got %d shots", competitor.TotalShots())
+	}
+
+	if competitor.LapTimes[0].Time != 0 {
+		t.Errorf("expected the lap end during a penalty to be rejected, got lap time %v", competitor.LapTimes[0].Time)
+	}
+
+	expectedAnomalies := []int{model.EventShot, model.EventLapEnd, model.EventLeavePenalty}
+	if len(result.Anomalies) != len(expectedAnomalies) {
+		t.Fatalf("expected %d anomalies, got %v", len(expectedAnomalies), result.Anomalies)
+	}
+	for i, anomaly := range result.Anomalies {
+		var processingErr *utils.ProcessingError
+		if !errors.As(anomaly.Err, &processingErr) {
+			t.Fatalf("anomaly[%d]: expected ProcessingError, got %v", i, anomaly.Err)
+		}
+		if processingErr.EventID != expectedAnomalies[i] {
+			t.Errorf("anomaly[%d]: expected event %d, got %d", i, expectedAnomalies[i], processingErr.EventID)
+		}
+	}
+}
+
+func TestTerminalStates(t *testing.T) {
+	ctx := context.Background()
+
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := baseTime.Add(10 * time.Hour)
+
+	cfg := config.Config{
+		Laps:       1,
+		LapLen:     3500,
+		Start:      "10:00:00.000",
+		StartDelta: "00:01:30",
+		Date:       "2025-01-01",
+	}
+
+	events := append(startEvents(1, start),
+		model.Event{Time: start.Add(20 * time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+		model.Event{Time: start.Add(25 * time.Minute), EventID: model.EventLostInForest, CompetitorID: 1, ExtraParams: "late"},
+		model.Event{Time: start.Add(26 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		model.Event{Time: baseTime.Add(9 * time.Hour), EventID: model.EventRegistration, CompetitorID: 2},
+		model.Event{Time: baseTime.Add(9 * time.Hour), EventID: model.EventSetStartTime, CompetitorID: 2, ExtraParams: "10:05:00.000"},
+	)
+
+	result := (&DefaultEventProcessor{}).Process(ctx, events, cfg)
+
+	finished := result.Competitors[1]
+	if finished.State != model.StateFinished || finished.Status != model.StatusFinished || finished.StatusComment != "" {
+		t.Errorf("competitor 1: expected %s, got state %s, status %s, comment %q",
+			model.StateFinished, finished.State, finished.Status, finished.StatusComment)
+	}
+	if len(finished.Anomalies) != 2 {
+		t.Fatalf("competitor 1: expected 2 anomalies, got %v", finished.Anomalies)
+	}
+	for _, anomaly := range finished.Anomalies {
+		if !strings.Contains(anomaly.Err.Error(), "after the race ended in state Finished") {
+			t.Errorf("competitor 1: unexpected anomaly %v", anomaly)
+		}
+	}
+
+	notStarted := result.Competitors[2]
+	if notStarted.State != model.StateNotStarted || notStarted.Status != model.StatusNotStarted {
+		t.Errorf("competitor 2: expected %s, got state %s, status %s", model.StateNotStarted, notStarted.State, notStarted.Status)
+	}
+}
+
+func TestGeneratedEventsRejected(t *testing.T) {
+	input := "[09:00:00.000] 1 1\n" +
+		"[09:10:00.000] 2 1 10:00:00.000\n" +
+		"[10:00:00.000] 4 1\n" +
+		"[10:01:00.000] 32 1\n" +
+		"[10:20:00.000] 10 1\n"
+
+	events, parseErrors, err := ReadEvents(strings.NewReader(input), ParseOptions{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+	if len(parseErrors) != 1 || parseErrors[0].Line != 4 || parseErrors[0].Column != 16 || !errors.Is(parseErrors[0], utils.ErrGeneratedEventID) {
+		t.Errorf("expected a generated event ID at line 4, column 16, got %v", parseErrors)
+	}
+
+	cfg := config.Config{Laps: 1, LapLen: 3500, Start: "10:00:00.000", StartDelta: "00:01:30", Date: "2025-01-01"}
+	disqualified := model.Event{Time: events[2].Time.Add(time.Minute), EventID: model.EventDisqualified, CompetitorID: 1}
+	result := ProcessEvents(context.Background(), append(events, disqualified), cfg)
+
+	competitor := result.Competitors[1]
+	if !competitor.IsFinished() {
+		t.Errorf("expected the competitor to finish, got %s", competitor.Status)
+	}
+	if len(competitor.Anomalies) != 2 || !competitor.Anomalies[1].Time.Equal(disqualified.Time) ||
+		!strings.Contains(competitor.Anomalies[1].Err.Error(), "only generated by the processor") {
+		t.Errorf("expected a generated event anomaly, got %v", competitor.Anomalies)
 	}
 }
 
@@ -199,6 +540,7 @@ func TestBasicParallelProcessing(t *testing.T) {
 		FiringLines: 2,
 		Start:       "10:00:00.000",
 		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
 	}
 	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 
@@ -215,7 +557,7 @@ func TestBasicParallelProcessing(t *testing.T) {
 		},
 	}
 
-	competitors := ProcessEventsParallel(ctx, events, cfg)
+	competitors := ProcessEventsParallel(ctx, events, cfg).Competitors
 
 	if len(competitors) != 2 {
 		t.Errorf("expected 2 competitors, got %d", len(competitors))
@@ -248,6 +590,7 @@ func TestParallelEvent(t *testing.T) {
 		FiringLines: 2,
 		Start:       "10:00:00.000",
 		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
 	}
 
 	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -263,7 +606,7 @@ func TestParallelEvent(t *testing.T) {
 			Time:         baseTime.Add(10*time.Hour + 1*time.Second),
 			EventID:      model.EventShot,
 			CompetitorID: 1,
-			ExtraParams:  model.ShotTarget1,
+			ExtraParams:  "1",
 		},
 		{
 			Time:         baseTime.Add(10 * time.Hour),
@@ -275,18 +618,21 @@ func TestParallelEvent(t *testing.T) {
 			Time:         baseTime.Add(10*time.Hour + 1*time.Second),
 			EventID:      model.EventShot,
 			CompetitorID: 2,
-			ExtraParams:  model.ShotTarget3,
+			ExtraParams:  "3",
 		},
 	}
 
-	competitors := ProcessEventsParallel(ctx, events, cfg)
+	start := baseTime.Add(9*time.Hour + 30*time.Minute)
+	events = append(append(events, startEvents(1, start)...), startEvents(2, start)...)
+
+	competitors := ProcessEventsParallel(ctx, events, cfg).Competitors
 	comp1, exists := competitors[1]
 	if !exists {
 		t.Fatalf("competitor 1 not found")
 	}
 
-	if comp1.ShotsHit != 1 {
-		t.Errorf("competitor 1: expected 1 shot hit, got %d", comp1.ShotsHit)
+	if comp1.ShotsHit() != 1 {
+		t.Errorf("competitor 1: expected 1 shot hit, got %d", comp1.ShotsHit())
 	}
 
 	comp2, exists := competitors[2]
@@ -294,11 +640,508 @@ func TestParallelEvent(t *testing.T) {
 		t.Fatalf("competitor 2 not found")
 	}
 
-	if comp2.ShotsHit != 0 {
-		t.Errorf("competitor 2: expected 0 shots hit, got %d", comp2.ShotsHit)
+	if comp2.ShotsHit() != 1 {
+		t.Errorf("competitor 2: expected 1 shot hit, got %d", comp2.ShotsHit())
+	}
+
+	if comp2.Firings[0].Misses != 4 {
+		t.Errorf("competitor 2: expected 4 misses, got %d", comp2.Firings[0].Misses)
 	}
+}
+
+func TestPenaltyShortfall(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	competitor := &model.Competitor{
+		ID:          1,
+		Status:      model.StatusRunning,
+		State:       model.StateRunning,
+		CurrentLap:  1,
+		ActualStart: baseTime,
+		LapTimes:    make([]model.LapInfo, cfg.Laps),
+	}
+
+	events := []model.Event{
+		{Time: baseTime.Add(10 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Minute + time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Minute + 2*time.Second), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Minute + 10*time.Second), EventID: model.EventEnterPenalty, CompetitorID: 1},
+		{Time: baseTime.Add(11 * time.Minute), EventID: model.EventLeavePenalty, CompetitorID: 1},
+		{Time: baseTime.Add(15 * time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+		{Time: baseTime.Add(20 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(20*time.Minute + time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(20*time.Minute + 2*time.Second), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: baseTime.Add(25 * time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+	}
+
+	var processedEvents []model.Event
+	for _, event := range events {
+		processEvent(competitor, event, cfg, calculateTimingParameters(cfg), &processedEvents)
+	}
+
+	if competitor.Penalties[0].Completed != 2 {
+		t.Errorf("expected 2 estimated penalty loops, got %d", competitor.Penalties[0].Completed)
+	}
+
+	var shortfalls []string
+	for _, event := range processedEvents {
+		if event.EventID == model.EventPenaltyShortfall {
+			shortfalls = append(shortfalls, event.ExtraParams)
+		}
+	}
+
+	if len(shortfalls) != 2 || shortfalls[0] != "2/4" || shortfalls[1] != "0/4" {
+		t.Errorf("expected shortfalls [2/4 0/4], got %v", shortfalls)
+	}
+
+	if !competitor.PenaltyShortfall {
+		t.Errorf("expected penalty shortfall flag to be set")
+	}
+}
+
+func TestSplitPenaltyStints(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Laps:        1,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	competitor := &model.Competitor{
+		ID:          1,
+		Status:      model.StatusRunning,
+		State:       model.StateRunning,
+		CurrentLap:  1,
+		ActualStart: baseTime,
+		LapTimes:    make([]model.LapInfo, cfg.Laps),
+	}
+
+	// Four misses served as two loops, then two more: each 40s stint fits
+	// two 150m loops at the 8 m/s limit.
+	events := []model.Event{
+		{Time: baseTime.Add(10 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Minute + time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Minute + 2*time.Second), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Minute + 10*time.Second), EventID: model.EventEnterPenalty, CompetitorID: 1},
+		{Time: baseTime.Add(10*time.Minute + 50*time.Second), EventID: model.EventLeavePenalty, CompetitorID: 1},
+		{Time: baseTime.Add(11 * time.Minute), EventID: model.EventEnterPenalty, CompetitorID: 1},
+		{Time: baseTime.Add(11*time.Minute + 40*time.Second), EventID: model.EventLeavePenalty, CompetitorID: 1},
+	}
+
+	var processedEvents []model.Event
+	for _, event := range events {
+		processEvent(competitor, event, cfg, calculateTimingParameters(cfg), &processedEvents)
+	}
+
+	if len(competitor.Penalties) != 2 || competitor.Penalties[0].Loops != 4 || competitor.Penalties[1].Loops != 2 {
+		t.Fatalf("expected stints owing 4 and 2 loops, got %+v", competitor.Penalties)
+	}
+	if competitor.Firings[0].PenaltyLoops != 4 {
+		t.Errorf("expected 4 penalty loops served, got %d", competitor.Firings[0].PenaltyLoops)
+	}
+
+	// Only the first stint falls short at the time it ends.
+	var shortfalls []model.Event
+	for _, event := range processedEvents {
+		if event.EventID == model.EventPenaltyShortfall {
+			shortfalls = append(shortfalls, event)
+		}
+	}
+	if len(shortfalls) != 1 || shortfalls[0].ExtraParams != "2/4" || !shortfalls[0].Time.Equal(events[4].Time) {
+		t.Errorf("expected a single 2/4 shortfall for the first stint, got %v", shortfalls)
+	}
+}
+
+func TestMissedStart(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastEventTime := baseTime.Add(10*time.Hour + 30*time.Minute)
+	startDelta := 90 * time.Second
+
+	drawn := &model.Competitor{
+		ID:           1,
+		Status:       model.StatusNotStarted,
+		PlannedStart: baseTime.Add(10 * time.Hour),
+	}
+	undrawn := &model.Competitor{
+		ID:     2,
+		Status: model.StatusNotStarted,
+	}
+	waiting := &model.Competitor{
+		ID:           3,
+		Status:       model.StatusNotStarted,
+		PlannedStart: lastEventTime.Add(-time.Minute),
+	}
+
+	var processedEvents []model.Event
+	checkMissedStart(drawn, lastEventTime, startDelta, &processedEvents)
+	checkMissedStart(undrawn, lastEventTime, startDelta, &processedEvents)
+	checkMissedStart(waiting, lastEventTime, startDelta, &processedEvents)
+
+	if len(processedEvents) != 1 {
+		t.Fatalf("expected 1 generated event, got %d", len(processedEvents))
+	}
+
+	notStarted := processedEvents[0]
+	if notStarted.EventID != model.EventNotStarted || notStarted.CompetitorID != 1 {
+		t.Errorf("expected not started event for competitor 1, got %+v", notStarted)
+	}
+
+	if !notStarted.Time.Equal(drawn.PlannedStart.Add(startDelta)) {
+		t.Errorf("expected event at the end of the start window, got %v", notStarted.Time)
+	}
+
+	if drawn.Status != model.StatusNotStarted || len(drawn.Anomalies) != 0 {
+		t.Errorf("competitor 1: unexpected state %s, anomalies %v", drawn.Status, drawn.Anomalies)
+	}
+
+	var processingErr *utils.ProcessingError
+	if len(undrawn.Anomalies) != 1 || !errors.Is(undrawn.Anomalies[0].Err, utils.ErrNoStartDrawn) ||
+		errors.As(undrawn.Anomalies[0].Err, &processingErr) || !undrawn.Anomalies[0].Time.Equal(lastEventTime) {
+		t.Errorf("competitor 2: expected a no start time anomaly not tied to an event, got %v", undrawn.Anomalies)
+	}
+}
+
+func TestProcessResult(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Config{
+		Laps:        1,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	events := []model.Event{
+		{Time: baseTime.Add(10*time.Hour + time.Minute), EventID: model.EventStarted, CompetitorID: 1},
+		{Time: baseTime.Add(9 * time.Hour), EventID: model.EventSetStartTime, CompetitorID: 1, ExtraParams: "10:00:00.000"},
+		{Time: baseTime.Add(10*time.Hour + 20*time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+		{Time: baseTime.Add(8 * time.Hour), EventID: model.EventRegistration, CompetitorID: 1},
+	}
+	input := slices.Clone(events)
+
+	result := ProcessEvents(ctx, events, cfg)
+
+	if !reflect.DeepEqual(events, input) {
+		t.Errorf("input events were modified: %v", events)
+	}
+
+	expectedLog := []int{model.EventRegistration, model.EventSetStartTime, model.EventStarted, model.EventFinished, model.EventLapEnd}
+	if len(result.OutputLog) != len(expectedLog) {
+		t.Fatalf("expected %d log events, got %d", len(expectedLog), len(result.OutputLog))
+	}
+	for i, event := range result.OutputLog {
+		if event.EventID != expectedLog[i] {
+			t.Errorf("log[%d]: expected EventID %d, got %d", i, expectedLog[i], event.EventID)
+		}
+	}
+
+	expectedStats := model.ProcessStats{ProcessedEvents: 4, GeneratedEvents: 1, Competitors: 1}
+	if result.Stats != expectedStats {
+		t.Errorf("expected stats %+v, got %+v", expectedStats, result.Stats)
+	}
+
+	if result.Competitors[1].Status != model.StatusFinished {
+		t.Errorf("expected status %s, got %s", model.StatusFinished, result.Competitors[1].Status)
+	}
+}
+
+func TestFiringRangeValidation(t *testing.T) {
+	ctx := context.Background()
+
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	start := baseTime.Add(10 * time.Hour)
+	events := []model.Event{
+		{Time: start.Add(5 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "2"},
+		{Time: start.Add(5*time.Minute + time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "6"},
+		{Time: start.Add(5*time.Minute + 2*time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "x"},
+		{Time: start.Add(5*time.Minute + 3*time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "1"},
+		{Time: start.Add(6 * time.Minute), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: start.Add(10 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "3"},
+		{Time: start.Add(11 * time.Minute), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: start.Add(15 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: start.Add(16 * time.Minute), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: start.Add(20 * time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+		{Time: start.Add(25 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: start.Add(26 * time.Minute), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: start.Add(40 * time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+		{Time: start.Add(10*time.Minute + time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "2"},
+	}
+
+	processor := &DefaultEventProcessor{}
+	competitor := processor.Process(ctx, append(startEvents(1, start), events...), cfg).Competitors[1]
+
+	anomalyAt := func(at time.Time) bool {
+		return slices.ContainsFunc(competitor.Anomalies, func(anomaly model.Anomaly) bool {
+			return anomaly.Time.Equal(at)
+		})
+	}
+
+	for i, name := range map[int]string{
+		0:  "out of order firing line",
+		1:  "target out of range",
+		2:  "malformed target",
+		5:  "firing line out of range",
+		7:  "line 1 visited second",
+		12: "missing firing range visit",
+	} {
+		if !anomalyAt(events[i].Time) {
+			t.Errorf("expected an anomaly for the %s, got %v", name, competitor.Anomalies)
+		}
+	}
+
+	for _, i := range []int{3, 10, 13} {
+		if anomalyAt(events[i].Time) {
+			t.Errorf("unexpected anomaly for event %+v: %v", events[i], competitor.Anomalies)
+		}
+	}
+
+	if competitor.ShotsHit() != 1 {
+		t.Errorf("expected only the valid target to count as a hit, got %d", competitor.ShotsHit())
+	}
+
+	// The visit to line 3 is not recorded, so its shot and misses count
+	// towards neither the shooting nor the penalty totals.
+	if len(competitor.Firings) != 3 || competitor.TotalShots() != 15 || competitor.Misses() != 14 {
+		t.Errorf("expected 3 recorded visits with 14 misses, got %+v", competitor.Firings)
+	}
+}
+
+func TestLapSplits(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	competitor := &model.Competitor{
+		ID:          1,
+		Status:      model.StatusRunning,
+		State:       model.StateRunning,
+		CurrentLap:  1,
+		ActualStart: baseTime,
+		LapTimes:    make([]model.LapInfo, cfg.Laps),
+	}
+
+	events := []model.Event{
+		{Time: baseTime.Add(10 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(10*time.Minute + time.Second), EventID: model.EventShot, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(11 * time.Minute), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: baseTime.Add(11*time.Minute + 10*time.Second), EventID: model.EventEnterPenalty, CompetitorID: 1},
+		{Time: baseTime.Add(13*time.Minute + 10*time.Second), EventID: model.EventLeavePenalty, CompetitorID: 1},
+		{Time: baseTime.Add(20 * time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+		{Time: baseTime.Add(25 * time.Minute), EventID: model.EventFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: baseTime.Add(25*time.Minute + 30*time.Second), EventID: model.EventLeaveFiring, CompetitorID: 1},
+		{Time: baseTime.Add(26 * time.Minute), EventID: model.EventEnterPenalty, CompetitorID: 1},
+		{Time: baseTime.Add(36 * time.Minute), EventID: model.EventLeavePenalty, CompetitorID: 1},
+		{Time: baseTime.Add(40 * time.Minute), EventID: model.EventLapEnd, CompetitorID: 1},
+	}
+
+	var processedEvents []model.Event
+	for _, event := range events {
+		processEvent(competitor, event, cfg, calculateTimingParameters(cfg), &processedEvents)
+	}
+
+	expected := []model.LapInfo{
+		{ToRange: 10 * time.Minute, RangeTime: time.Minute, PenaltyTime: 2 * time.Minute, SkiTime: 17 * time.Minute},
+		{ToRange: 5 * time.Minute, RangeTime: 30 * time.Second, PenaltyTime: 10 * time.Minute, SkiTime: 9*time.Minute + 30*time.Second},
+	}
+
+	for i, want := range expected {
+		got := competitor.LapTimes[i]
+		if got.ToRange != want.ToRange || got.RangeTime != want.RangeTime ||
+			got.PenaltyTime != want.PenaltyTime || got.SkiTime != want.SkiTime {
+			t.Errorf("lap %d: expected splits %+v, got %+v", i+1, want, got)
+		}
+	}
+}
+
+// unregisterOnCleanup drops event types a test registers, so the suite can
+// run more than once in a process.
+func unregisterOnCleanup(t *testing.T, ids ...int) {
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		for _, id := range ids {
+			delete(registry, id)
+		}
+	})
+}
+
+// equipmentPayload is the payload of a custom event type, e.g.
+// "[09:10:00.000] 40 1 skis ok".
+type equipmentPayload struct {
+	Item   string
+	Passed bool
+}
+
+func (equipmentPayload) IsPayload() {}
+
+func decodeEquipment(event model.Event, clock *utils.Clock) (model.Payload, error) {
+	fields := strings.Fields(event.ExtraParams)
+	if len(fields) != 2 || (fields[1] != "ok" && fields[1] != "failed") {
+		return nil, fmt.Errorf("%w: equipment check %q", utils.ErrInvalidEventParams, event.ExtraParams)
+	}
+	return equipmentPayload{Item: fields[0], Passed: fields[1] == "ok"}, nil
+}
+
+func TestCustomEventType(t *testing.T) {
+	const equipmentCheck = 40
+	unregisterOnCleanup(t, equipmentCheck)
+
+	err := Register(EventType{
+		ID:     equipmentCheck,
+		Name:   "EquipmentCheck",
+		Decode: decodeEquipment,
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			if payload := event.Payload.(equipmentPayload); !payload.Passed {
+				ctx.Anomaly(competitor, event, "equipment check failed: "+payload.Item)
+				ctx.Disqualify(competitor, event)
+			}
+		},
+		Describe: func(event model.Event) string {
+			payload := event.Payload.(equipmentPayload)
+			return fmt.Sprintf("The %s of competitor(%d) passed the equipment check: %t", payload.Item, event.CompetitorID, payload.Passed)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := Register(EventType{ID: equipmentCheck, Name: "Duplicate"}); err == nil {
+		t.Errorf("expected an error when registering an ID twice")
+	}
+
+	input := "[09:00:00.000] 1 1\n" +
+		"[09:10:00.000] 40 1 skis ok\n" +
+		"[09:20:00.000] 40 1 rifle failed\n" +
+		"[09:25:00.000] 40 1 rifle\n" +
+		"[09:30:00.000] 41 1\n"
+
+	events, parseErrors, err := ReadEvents(strings.NewReader(input), ParseOptions{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+	if len(parseErrors) != 2 ||
+		parseErrors[0].Line != 4 || !errors.Is(parseErrors[0], utils.ErrInvalidEventParams) ||
+		parseErrors[1].Line != 5 || !errors.Is(parseErrors[1], utils.ErrUnknownEventID) {
+		t.Errorf("expected invalid params on line 4 and an unknown event ID on line 5, got %v", parseErrors)
+	}
+	if len(events) != 3 || events[1].Payload != (equipmentPayload{Item: "skis", Passed: true}) {
+		t.Fatalf("expected the equipment check payload to be decoded, got %+v", events)
+	}
+
+	cfg := config.Config{Laps: 1, Start: "10:00:00.000", StartDelta: "00:01:30", Date: "2025-01-01"}
+	events = append(events, model.Event{Time: events[2].Time.Add(time.Minute), EventID: 42, CompetitorID: 1})
+	result := ProcessEvents(context.Background(), events, cfg)
+
+	var generated []model.Event
+	var described []string
+	for _, logged := range result.OutputLog {
+		if logged.Processed && logged.EventID == model.EventDisqualified {
+			generated = append(generated, logged)
+		}
+		if logged.EventID == equipmentCheck {
+			described = append(described, DescribeEvent(logged))
+		}
+	}
+	if len(generated) != 1 || !generated[0].Time.Equal(events[2].Time) {
+		t.Errorf("expected one generated disqualification, got %v", generated)
+	}
+	if competitor := result.Competitors[1]; competitor.State != model.StateDisqualified || !competitor.IsDisqualified() {
+		t.Errorf("expected the failed check to disqualify, got state %s, status %s", competitor.State, competitor.Status)
+	}
+
+	expectedLog := []string{
+		"The skis of competitor(1) passed the equipment check: true",
+		"The rifle of competitor(1) passed the equipment check: false",
+	}
+	if !slices.Equal(described, expectedLog) {
+		t.Errorf("expected log descriptions %q, got %q", expectedLog, described)
+	}
+
+	anomalies := result.Competitors[1].Anomalies
+	hasAnomaly := func(at time.Time, text string) bool {
+		return slices.ContainsFunc(anomalies, func(anomaly model.Anomaly) bool {
+			return anomaly.Time.Equal(at) && strings.Contains(anomaly.Err.Error(), text)
+		})
+	}
+	if !hasAnomaly(events[2].Time, "equipment check failed: rifle") {
+		t.Errorf("expected a failed equipment check anomaly, got %v", anomalies)
+	}
+	if !hasAnomaly(events[3].Time, "unknown event") {
+		t.Errorf("expected an unknown event anomaly, got %v", anomalies)
+	}
+
+	if EventName(equipmentCheck) != "EquipmentCheck" {
+		t.Errorf("unexpected name %s", EventName(equipmentCheck))
+	}
+}
+
+func TestRegisterDuringProcessing(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	cfg := config.Config{Laps: 1, LapLen: 3500, Start: "10:00:00.000", StartDelta: "00:01:30", Date: "2025-01-01"}
+
+	var events []model.Event
+	for id := 1; id <= 20; id++ {
+		events = append(events, startEvents(id, start.Add(time.Duration(id)*time.Minute))...)
+	}
+
+	customIDs := make([]int, 0, 10)
+	for id := 60; id < 70; id++ {
+		customIDs = append(customIDs, id)
+	}
+	unregisterOnCleanup(t, customIDs...)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, id := range customIDs {
+			if err := Register(EventType{ID: id, Name: fmt.Sprintf("Custom%d", id)}); err != nil {
+				t.Errorf("Register(%d) failed: %v", id, err)
+			}
+		}
+	}()
+
+	result := ProcessEventsParallel(context.Background(), events, cfg)
+	<-done
 
-	if comp2.MissedShot != true {
-		t.Errorf("competitor 2: expected missed shot to be true")
+	if len(result.Competitors) != 20 || EventName(69) != "Custom69" {
+		t.Errorf("expected 20 competitors and registered custom types, got %d, %s", len(result.Competitors), EventName(69))
 	}
 }
@@ -0,0 +1,276 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// EventType describes one event ID: how its parameters decode into a
+// payload, how the processor applies it to a competitor and how the output
+// log describes it. Decode and Handle may be nil for events without
+// parameters or without an effect on the competitor. Generated types are
+// only ever emitted by the processor and are rejected in input.
+type EventType struct {
+	ID        int
+	Name      string
+	Generated bool
+	Decode    func(event model.Event, clock *utils.Clock) (model.Payload, error)
+	Handle    func(competitor *model.Competitor, event model.Event, ctx *HandlerContext)
+	Describe  func(event model.Event) string
+}
+
+// HandlerContext gives a handler the race configuration and lets it emit
+// generated events and flag anomalies.
+type HandlerContext struct {
+	Config          config.Config
+	timing          raceTiming
+	processedEvents *[]model.Event
+}
+
+func (c *HandlerContext) Emit(event model.Event) {
+	event.Processed = true
+	*c.processedEvents = append(*c.processedEvents, event)
+}
+
+func (c *HandlerContext) Anomaly(competitor *model.Competitor, event model.Event, message string) {
+	addAnomaly(competitor, event, message)
+}
+
+// SetState moves the competitor to state, updating its status to match;
+// no further events are accepted once the state is terminal.
+func (c *HandlerContext) SetState(competitor *model.Competitor, state model.State) {
+	setState(competitor, state)
+}
+
+// Disqualify ends the competitor's race at the event and logs the
+// disqualification.
+func (c *HandlerContext) Disqualify(competitor *model.Competitor, event model.Event) {
+	c.SetState(competitor, model.StateDisqualified)
+	c.Emit(model.Event{
+		Time:         event.Time,
+		EventID:      model.EventDisqualified,
+		CompetitorID: competitor.ID,
+	})
+}
+
+// registry is read by every parser and processor goroutine, so Register
+// may run concurrently with a race being processed.
+var (
+	registryMu sync.RWMutex
+	registry   = map[int]EventType{}
+)
+
+// Register adds an event type; registering an ID twice is an error. Events
+// parsed before their type is registered are rejected as unknown.
+func Register(eventType EventType) error {
+	if eventType.ID <= 0 || eventType.Name == "" {
+		return fmt.Errorf("event type needs a positive ID and a name: %+v", eventType)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if existing, ok := registry[eventType.ID]; ok {
+		return fmt.Errorf("event ID %d is already registered as %s", eventType.ID, existing.Name)
+	}
+	registry[eventType.ID] = eventType
+	return nil
+}
+
+func LookupEventType(id int) (EventType, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	eventType, ok := registry[id]
+	return eventType, ok
+}
+
+func EventName(id int) string {
+	if eventType, ok := LookupEventType(id); ok {
+		return eventType.Name
+	}
+	return fmt.Sprintf("Unknown(%d)", id)
+}
+
+func DescribeEvent(event model.Event) string {
+	if eventType, ok := LookupEventType(event.EventID); ok && eventType.Describe != nil {
+		return eventType.Describe(event)
+	}
+	return fmt.Sprintf("Unknown event(%d) for competitor(%d)", event.EventID, event.CompetitorID)
+}
+
+// checkEventID accepts the IDs that may appear in input.
+func checkEventID(id int) error {
+	if id <= 0 {
+		return utils.ErrInvalidEventID
+	}
+	eventType, ok := LookupEventType(id)
+	if !ok {
+		return utils.ErrUnknownEventID
+	}
+	if eventType.Generated {
+		return utils.ErrGeneratedEventID
+	}
+	return nil
+}
+
+func init() {
+	for _, eventType := range builtinEventTypes {
+		if err := Register(eventType); err != nil {
+			panic(err)
+		}
+	}
+}
+
+var builtinEventTypes = []EventType{
+	{
+		ID:   model.EventRegistration,
+		Name: "Registration",
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleRegistrationEvent(competitor, event)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) registered", event.CompetitorID)
+		},
+	},
+	{
+		ID:     model.EventSetStartTime,
+		Name:   "SetStartTime",
+		Decode: decodeStartTime,
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleSetStartTimeEvent(competitor, event, ctx.timing)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The start time for the competitor(%d) was set by a draw to %s", event.CompetitorID, event.ExtraParams)
+		},
+	},
+	{
+		ID:   model.EventStartLine,
+		Name: "StartLine",
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleStartLineEvent(competitor, event)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) is on the start line", event.CompetitorID)
+		},
+	},
+	{
+		ID:   model.EventStarted,
+		Name: "Started",
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleStartedEvent(competitor, event, ctx.timing.startDelta, ctx.processedEvents)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) has started", event.CompetitorID)
+		},
+	},
+	{
+		ID:     model.EventFiringRange,
+		Name:   "FiringRange",
+		Decode: decodeFiringRange,
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleFiringRangeEvent(competitor, event, ctx.Config)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) is on the firing range(%s)", event.CompetitorID, event.ExtraParams)
+		},
+	},
+	{
+		ID:     model.EventShot,
+		Name:   "Shot",
+		Decode: decodeShot,
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleShotEvent(competitor, event)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The target(%s) has been hit by competitor(%d)", event.ExtraParams, event.CompetitorID)
+		},
+	},
+	{
+		ID:   model.EventLeaveFiring,
+		Name: "LeaveFiring",
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleLeaveFireEvent(competitor, event)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) left the firing range", event.CompetitorID)
+		},
+	},
+	{
+		ID:   model.EventEnterPenalty,
+		Name: "EnterPenalty",
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleEnterPenaltyEvent(competitor, event)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) entered the penalty laps", event.CompetitorID)
+		},
+	},
+	{
+		ID:   model.EventLeavePenalty,
+		Name: "LeavePenalty",
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleLeavePenaltyEvent(competitor, event, ctx.Config, ctx.processedEvents)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) left the penalty laps", event.CompetitorID)
+		},
+	},
+	{
+		ID:   model.EventLapEnd,
+		Name: "LapEnd",
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleLapEndEvent(competitor, event, ctx.Config, ctx.processedEvents)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) ended the main lap", event.CompetitorID)
+		},
+	},
+	{
+		ID:     model.EventLostInForest,
+		Name:   "LostInForest",
+		Decode: decodeComment,
+		Handle: func(competitor *model.Competitor, event model.Event, ctx *HandlerContext) {
+			handleLostEvent(competitor, event)
+		},
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) can`t continue: %s", event.CompetitorID, event.ExtraParams)
+		},
+	},
+	{
+		ID:        model.EventDisqualified,
+		Name:      "Disqualified",
+		Generated: true,
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) is disqualified", event.CompetitorID)
+		},
+	},
+	{
+		ID:        model.EventFinished,
+		Name:      "Finished",
+		Generated: true,
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) has finished", event.CompetitorID)
+		},
+	},
+	{
+		ID:        model.EventPenaltyShortfall,
+		Name:      "PenaltyShortfall",
+		Generated: true,
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) skied too few penalty loops (%s)", event.CompetitorID, event.ExtraParams)
+		},
+	},
+	{
+		ID:        model.EventNotStarted,
+		Name:      "NotStarted",
+		Generated: true,
+		Describe: func(event model.Event) string {
+			return fmt.Sprintf("The competitor(%d) did not start", event.CompetitorID)
+		},
+	},
+}
@@ -0,0 +1,99 @@
+package event
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// transitions lists, per incoming event, the competitor states it may
+// arrive in and the state it moves the competitor to. Handlers may still
+// end the race afterwards, e.g. a late start disqualifies.
+var transitions = map[int]map[model.State]model.State{
+	model.EventRegistration: {
+		model.StateUnregistered: model.StateRegistered,
+	},
+	model.EventSetStartTime: {
+		model.StateRegistered: model.StateDrawn,
+		model.StateDrawn:      model.StateDrawn,
+	},
+	model.EventStartLine: {
+		model.StateDrawn: model.StateOnStartLine,
+	},
+	model.EventStarted: {
+		model.StateDrawn:       model.StateRunning,
+		model.StateOnStartLine: model.StateRunning,
+	},
+	model.EventFiringRange: {
+		model.StateRunning: model.StateOnRange,
+	},
+	model.EventShot: {
+		model.StateOnRange: model.StateOnRange,
+	},
+	model.EventLeaveFiring: {
+		model.StateOnRange: model.StateRunning,
+	},
+	model.EventEnterPenalty: {
+		model.StateRunning: model.StateInPenalty,
+	},
+	model.EventLeavePenalty: {
+		model.StateInPenalty: model.StateRunning,
+	},
+	model.EventLapEnd: {
+		model.StateRunning: model.StateRunning,
+	},
+	model.EventLostInForest: {
+		model.StateUnregistered: model.StateNotFinished,
+		model.StateRegistered:   model.StateNotFinished,
+		model.StateDrawn:        model.StateNotFinished,
+		model.StateOnStartLine:  model.StateNotFinished,
+		model.StateRunning:      model.StateNotFinished,
+		model.StateOnRange:      model.StateNotFinished,
+		model.StateInPenalty:    model.StateNotFinished,
+	},
+}
+
+// transition moves the competitor to the state the event leads to, or
+// leaves it untouched and returns a utils.ProcessingError when the event is
+// not legal in the current state. No event is legal once the race has
+// ended for the competitor.
+func transition(competitor *model.Competitor, event model.Event) error {
+	if competitor.State.IsTerminal() {
+		return utils.NewProcessingError(competitor.ID, event.EventID,
+			fmt.Sprintf("event after the race ended in state %s", competitor.State))
+	}
+
+	allowed, known := transitions[event.EventID]
+	if !known {
+		return nil
+	}
+
+	next, ok := allowed[competitor.State]
+	if !ok {
+		return utils.NewProcessingError(competitor.ID, event.EventID,
+			fmt.Sprintf("event not allowed in state %s", competitor.State))
+	}
+
+	setState(competitor, next)
+	return nil
+}
+
+// setState is the only place a competitor's state changes; Status always
+// follows it.
+func setState(competitor *model.Competitor, state model.State) {
+	competitor.State = state
+	competitor.Status = state.Status()
+}
+
+func flagAnomaly(competitor *model.Competitor, at time.Time, err error) {
+	competitor.Anomalies = append(competitor.Anomalies, model.Anomaly{
+		Time: at,
+		Err:  err,
+	})
+}
+
+func addAnomaly(competitor *model.Competitor, event model.Event, message string) {
+	flagAnomaly(competitor, event.Time, utils.NewProcessingError(competitor.ID, event.EventID, message))
+}
@@ -2,8 +2,9 @@ package event
 
 import (
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
-func ExportedParseEvent(line string) (model.Event, error) {
-	return parseEvent(line)
+func ExportedParseEvent(line string, clock *utils.Clock) (model.Event, error) {
+	return parseEvent(line, clock)
 }
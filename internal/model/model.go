@@ -20,15 +20,12 @@ const (
 	EventDisqualified = 32
 	EventFinished     = 33
 
-	ShotTarget1 = "1"
-	ShotTarget2 = "2"
-	ShotTarget3 = "3"
-	ShotTarget4 = "4"
-	ShotTarget5 = "5"
+	EventPenaltyShortfall = 34
+	EventNotStarted       = 35
 
-	TimeFormat       = "15:04:05.000"
-	ZeroTimeString   = "00:00:00.000"
-	LostInForestText = "Lost in the forest"
+	TargetsPerLine = 5
+
+	TimeFormat = "15:04:05.000"
 
 	StatusFinished     = "Finished"
 	StatusNotFinished  = "NotFinished"
@@ -37,34 +34,131 @@ const (
 	StatusDisqualified = "Disqualified"
 )
 
+type State int
+
+const (
+	StateUnregistered State = iota
+	StateRegistered
+	StateDrawn
+	StateOnStartLine
+	StateRunning
+	StateOnRange
+	StateInPenalty
+	StateFinished
+	StateNotFinished
+	StateNotStarted
+	StateDisqualified
+)
+
+var stateNames = [...]string{
+	StateUnregistered: "Unregistered",
+	StateRegistered:   "Registered",
+	StateDrawn:        "Drawn",
+	StateOnStartLine:  "OnStartLine",
+	StateRunning:      "Running",
+	StateOnRange:      "OnRange",
+	StateInPenalty:    "InPenalty",
+	StateFinished:     "Finished",
+	StateNotFinished:  "NotFinished",
+	StateNotStarted:   "NotStarted",
+	StateDisqualified: "Disqualified",
+}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+	return stateNames[s]
+}
+
+func (s State) IsTerminal() bool {
+	return s >= StateFinished
+}
+
+// Status is the result status reported for a competitor in this state.
+func (s State) Status() string {
+	switch s {
+	case StateRunning, StateOnRange, StateInPenalty:
+		return StatusRunning
+	case StateFinished:
+		return StatusFinished
+	case StateNotFinished:
+		return StatusNotFinished
+	case StateDisqualified:
+		return StatusDisqualified
+	default:
+		return StatusNotStarted
+	}
+}
+
 type Competitor struct {
-	ID             int
-	CurrentLap     int
-	CurrentFiring  int
-	ShotsHit       int
-	TotalShots     int
-	InPenalty      bool
-	OnFiringRange  bool
-	MissedShot     bool
-	Status         string
-	StatusComment  string
-	RegisteredTime time.Time
-	PlannedStart   time.Time
-	ActualStart    time.Time
-	LapTimes       []LapInfo
-	PenaltyLapInfo PenaltyInfo
+	ID               int
+	CurrentLap       int
+	CurrentFiring    int
+	State            State
+	Status           string
+	StatusComment    string
+	PenaltyShortfall bool
+	RegisteredTime   time.Time
+	PlannedStart     time.Time
+	StartLineTime    time.Time
+	ActualStart      time.Time
+	LapTimes         []LapInfo
+	Firings          []FiringRecord
+	Penalties        []PenaltyInfo
+	Anomalies        []Anomaly
 }
 
+// LapInfo splits Time into the time skied before the first firing range,
+// time spent on the ranges, time in the penalty loops and the remaining
+// pure ski time. Elapsed is set instead of Time on a lap the competitor
+// started but abandoned.
 type LapInfo struct {
-	Time   time.Duration
-	Speed  float64
-	Finish time.Time
+	Time        time.Duration
+	Elapsed     time.Duration
+	Speed       float64
+	Finish      time.Time
+	ToRange     time.Duration
+	RangeTime   time.Duration
+	PenaltyTime time.Duration
+	SkiTime     time.Duration
+}
+
+// FiringRecord is one firing range visit. PenaltyLoops counts the loops
+// skied in the penalty stints served for its misses so far.
+type FiringRecord struct {
+	Line         int
+	TargetsHit   []int
+	Misses       int
+	PenaltyLoops int
+	EnterTime    time.Time
+	LeaveTime    time.Time
 }
 
 type PenaltyInfo struct {
 	StartTime time.Time
 	Duration  time.Duration
 	Speed     float64
+	Loops     int
+	Completed int
+}
+
+type Anomaly struct {
+	Time time.Time
+	Err  error
+}
+
+type ProcessResult struct {
+	Competitors map[int]*Competitor
+	OutputLog   []Event
+	Anomalies   []Anomaly
+	Stats       ProcessStats
+}
+
+type ProcessStats struct {
+	ProcessedEvents int
+	GeneratedEvents int
+	Competitors     int
 }
 
 type Event struct {
@@ -72,6 +166,7 @@ type Event struct {
 	EventID      int
 	CompetitorID int
 	ExtraParams  string
+	Payload      Payload
 	Processed    bool
 }
 
@@ -83,6 +178,13 @@ func (c *Competitor) TotalTime() time.Duration {
 	return totalTime
 }
 
+func (c *Competitor) TimeOnStartLine() time.Duration {
+	if c.StartLineTime.IsZero() || c.ActualStart.IsZero() {
+		return 0
+	}
+	return c.ActualStart.Sub(c.StartLineTime)
+}
+
 func (c *Competitor) IsFinished() bool {
 	return c.Status == StatusFinished
 }
@@ -95,6 +197,58 @@ func (c *Competitor) IsDisqualified() bool {
 	return c.Status == StatusDisqualified
 }
 
+func (c *Competitor) LastFiring() *FiringRecord {
+	if len(c.Firings) == 0 {
+		return nil
+	}
+	return &c.Firings[len(c.Firings)-1]
+}
+
+func (c *Competitor) LastPenalty() *PenaltyInfo {
+	if len(c.Penalties) == 0 {
+		return nil
+	}
+	return &c.Penalties[len(c.Penalties)-1]
+}
+
+func (c *Competitor) Misses() int {
+	misses := 0
+	for _, firing := range c.Firings {
+		misses += firing.Misses
+	}
+	return misses
+}
+
+func (c *Competitor) PenaltyTime() time.Duration {
+	var penaltyTime time.Duration
+	for _, penalty := range c.Penalties {
+		penaltyTime += penalty.Duration
+	}
+	return penaltyTime
+}
+
+// PenaltySpeed is the average speed over all penalty loops the competitor
+// owes for missed shots, which is how official results compute it.
+func (c *Competitor) PenaltySpeed(penaltyLen int) float64 {
+	penaltyTime := c.PenaltyTime()
+	if penaltyTime <= 0 {
+		return 0
+	}
+	return float64(penaltyLen*c.Misses()) / penaltyTime.Seconds()
+}
+
+func (c *Competitor) ShotsHit() int {
+	hits := 0
+	for _, firing := range c.Firings {
+		hits += len(firing.TargetsHit)
+	}
+	return hits
+}
+
+func (c *Competitor) TotalShots() int {
+	return len(c.Firings) * TargetsPerLine
+}
+
 func (c *Competitor) ShotAccuracy() string {
-	return fmt.Sprintf("%d/%d", c.ShotsHit, c.TotalShots)
+	return fmt.Sprintf("%d/%d", c.ShotsHit(), c.TotalShots())
 }
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Payload is the decoded form of an event's ExtraParams. Events without
+// parameters carry a nil payload. Custom event types registered outside
+// this package declare their own payload types by implementing IsPayload.
+type Payload interface {
+	IsPayload()
+}
+
+type StartTimePayload struct {
+	Time time.Time
+}
+
+type FiringRangePayload struct {
+	Line int
+}
+
+type ShotPayload struct {
+	Target int
+}
+
+type CommentPayload struct {
+	Text string
+}
+
+func (StartTimePayload) IsPayload()   {}
+func (FiringRangePayload) IsPayload() {}
+func (ShotPayload) IsPayload()        {}
+func (CommentPayload) IsPayload()     {}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// CSVReporter writes the event log and the results as CSV tables. Lap times
+// go to a separate table written by OutputLaps.
+type CSVReporter struct{}
+
+func (r *CSVReporter) OutputLog(w io.Writer, result model.ProcessResult) error {
+	records := [][]string{{"time", "event", "name", "competitor", "params"}}
+	for _, event := range result.OutputLog {
+		records = append(records, []string{
+			utils.FormatTimeRFC(event.Time),
+			strconv.Itoa(event.EventID),
+			getEventName(event.EventID),
+			strconv.Itoa(event.CompetitorID),
+			event.ExtraParams,
+		})
+	}
+	return writeCSV(w, records)
+}
+
+func (r *CSVReporter) OutputFinalReport(w io.Writer, result model.ProcessResult, cfg config.Config) error {
+	records := [][]string{{"place", "competitor", "status", "total_time", "gap", "hits", "shots", "penalty_time", "penalty_speed"}}
+	for _, standing := range computeStandings(result.Competitors) {
+		comp := standing.Competitor
+
+		var place, totalTime, gap string
+		if standing.Place > 0 {
+			place = strconv.Itoa(standing.Place)
+			totalTime = utils.FormatDuration(comp.TotalTime())
+			gap = formatPositiveDuration(standing.Gap)
+		}
+
+		var penaltyTime, penaltySpeed string
+		if comp.PenaltyTime() > 0 {
+			penaltyTime = utils.FormatDuration(comp.PenaltyTime())
+			penaltySpeed = formatSpeed(comp.PenaltySpeed(cfg.PenaltyLen))
+		}
+
+		records = append(records, []string{
+			place,
+			strconv.Itoa(comp.ID),
+			comp.Status,
+			totalTime,
+			gap,
+			strconv.Itoa(comp.ShotsHit()),
+			strconv.Itoa(comp.TotalShots()),
+			penaltyTime,
+			penaltySpeed,
+		})
+	}
+	return writeCSV(w, records)
+}
+
+// OutputLaps writes one row per lap of every competitor, in results order.
+// Laps that were not completed are left blank.
+func (r *CSVReporter) OutputLaps(w io.Writer, result model.ProcessResult) error {
+	records := [][]string{{"competitor", "lap", "lap_time", "speed", "finish_time"}}
+	for _, comp := range sortCompetitors(result.Competitors) {
+		for i, lap := range comp.LapTimes {
+			record := []string{strconv.Itoa(comp.ID), strconv.Itoa(i + 1), "", "", ""}
+			if lap.Time > 0 {
+				record[2] = utils.FormatDuration(lap.Time)
+				record[3] = formatSpeed(lap.Speed)
+				record[4] = utils.FormatTimeRFC(lap.Finish)
+			}
+			records = append(records, record)
+		}
+	}
+	return writeCSV(w, records)
+}
+
+func formatSpeed(speed float64) string {
+	return strconv.FormatFloat(speed, 'f', 3, 64)
+}
+
+func writeCSV(w io.Writer, records [][]string) error {
+	writer := csv.NewWriter(w)
+	return writer.WriteAll(records)
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+//go:embed templates/report.html
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlTemplateText))
+
+// HTMLReporter renders a self-contained results page. The final report page
+// embeds the event log, so OutputLog is only needed for a separate log page.
+type HTMLReporter struct{}
+
+type htmlPage struct {
+	Title       string
+	Competitors []htmlCompetitor
+	Anomalies   []jsonAnomaly
+	Events      []jsonEvent
+}
+
+type htmlCompetitor struct {
+	Place            string
+	PlaceSort        int
+	ID               int
+	Status           string
+	StatusSort       int
+	StatusClass      string
+	TotalTime        string
+	Gap              string
+	Hits             int
+	Shots            int
+	PenaltyTime      string
+	PenaltySpeed     string
+	PenaltyShortfall bool
+	Laps             []htmlLap
+}
+
+type htmlLap struct {
+	Lap         int
+	Time        string
+	Speed       string
+	Rank        string
+	ToRange     string
+	RangeTime   string
+	PenaltyTime string
+	SkiTime     string
+}
+
+func (r *HTMLReporter) OutputLog(w io.Writer, result model.ProcessResult) error {
+	return htmlTemplate.Execute(w, htmlPage{
+		Title:  "Event log",
+		Events: htmlEvents(result.OutputLog),
+	})
+}
+
+func (r *HTMLReporter) OutputFinalReport(w io.Writer, result model.ProcessResult, cfg config.Config) error {
+	page := htmlPage{
+		Title:  strings.TrimSpace("Results " + cfg.Date),
+		Events: htmlEvents(result.OutputLog),
+	}
+
+	for i, standing := range computeStandings(result.Competitors) {
+		page.Competitors = append(page.Competitors, newHTMLCompetitor(i+1, standing, cfg))
+	}
+	for _, anomaly := range result.Anomalies {
+		page.Anomalies = append(page.Anomalies, jsonAnomaly{
+			Time:    utils.FormatTimeRFC(anomaly.Time),
+			Message: anomaly.Err.Error(),
+		})
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+func htmlEvents(events []model.Event) []jsonEvent {
+	rows := make([]jsonEvent, len(events))
+	for i, event := range events {
+		rows[i] = jsonEvent{
+			Time:         utils.FormatTimeRFC(event.Time),
+			EventID:      event.EventID,
+			Name:         getEventName(event.EventID),
+			CompetitorID: event.CompetitorID,
+			Description:  getEventDescription(event),
+		}
+	}
+	return rows
+}
+
+func newHTMLCompetitor(order int, standing Standing, cfg config.Config) htmlCompetitor {
+	comp := standing.Competitor
+	competitor := htmlCompetitor{
+		Place:            formatPlace(standing.Place),
+		PlaceSort:        order,
+		ID:               comp.ID,
+		Status:           comp.Status,
+		StatusSort:       statusOrder[comp.Status],
+		StatusClass:      "status-" + strings.ToLower(comp.Status),
+		Hits:             comp.ShotsHit(),
+		Shots:            comp.TotalShots(),
+		PenaltyTime:      formatPositiveDuration(comp.PenaltyTime()),
+		PenaltyShortfall: comp.PenaltyShortfall,
+	}
+
+	if comp.IsFinished() {
+		competitor.TotalTime = utils.FormatDuration(comp.TotalTime())
+		competitor.Gap = formatGap(standing)
+	}
+	if comp.PenaltyTime() > 0 {
+		competitor.PenaltySpeed = formatSpeed(comp.PenaltySpeed(cfg.PenaltyLen))
+	}
+
+	for i, lap := range comp.LapTimes {
+		row := htmlLap{Lap: i + 1, Rank: formatPlace(standing.LapRanks[i])}
+		if lap.Time > 0 {
+			row.Time = utils.FormatDuration(lap.Time)
+			row.Speed = formatSpeed(lap.Speed)
+			row.ToRange = formatPositiveDuration(lap.ToRange)
+			row.RangeTime = formatPositiveDuration(lap.RangeTime)
+			row.PenaltyTime = formatPositiveDuration(lap.PenaltyTime)
+			row.SkiTime = formatPositiveDuration(lap.SkiTime)
+		}
+		competitor.Laps = append(competitor.Laps, row)
+	}
+
+	return competitor
+}
@@ -1,21 +1,23 @@
 package report
 
 import (
+	"io"
+
 	"github.com/niklvdanya/BiathlonTracker/internal/config"
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
 )
 
 type Reporter interface {
-	OutputLog(events []model.Event)
-	OutputFinalReport(competitors map[int]*model.Competitor, cfg config.Config)
+	OutputLog(w io.Writer, result model.ProcessResult) error
+	OutputFinalReport(w io.Writer, result model.ProcessResult, cfg config.Config) error
 }
 
 type DefaultReporter struct{}
 
-func (r *DefaultReporter) OutputLog(events []model.Event) {
-	OutputLog(events)
+func (r *DefaultReporter) OutputLog(w io.Writer, result model.ProcessResult) error {
+	return OutputLog(w, result.OutputLog)
 }
 
-func (r *DefaultReporter) OutputFinalReport(competitors map[int]*model.Competitor, cfg config.Config) {
-	OutputFinalReport(competitors, cfg)
+func (r *DefaultReporter) OutputFinalReport(w io.Writer, result model.ProcessResult, cfg config.Config) error {
+	return OutputFinalReport(w, result, cfg)
 }
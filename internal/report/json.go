@@ -0,0 +1,182 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// JSONReporter writes the same data as DefaultReporter as indented JSON.
+// Times are clock strings and durations are HH:MM:SS.mmm, as in the text
+// report.
+type JSONReporter struct{}
+
+type jsonEvent struct {
+	Time         string `json:"time"`
+	EventID      int    `json:"event"`
+	Name         string `json:"name"`
+	CompetitorID int    `json:"competitor"`
+	Params       string `json:"params,omitempty"`
+	Description  string `json:"description"`
+}
+
+type jsonReport struct {
+	Competitors []jsonCompetitor `json:"competitors"`
+	Anomalies   []jsonAnomaly    `json:"anomalies,omitempty"`
+}
+
+type jsonCompetitor struct {
+	Place            int           `json:"place,omitempty"`
+	ID               int           `json:"id"`
+	Status           string        `json:"status"`
+	Comment          string        `json:"comment,omitempty"`
+	TotalTime        string        `json:"totalTime,omitempty"`
+	Gap              string        `json:"gap,omitempty"`
+	PlannedStart     string        `json:"plannedStart,omitempty"`
+	ActualStart      string        `json:"actualStart,omitempty"`
+	Laps             []jsonLap     `json:"laps"`
+	PenaltyTime      string        `json:"penaltyTime,omitempty"`
+	PenaltySpeed     float64       `json:"penaltySpeed,omitempty"`
+	Penalties        []jsonPenalty `json:"penalties"`
+	Shooting         []jsonFiring  `json:"shooting"`
+	Hits             int           `json:"hits"`
+	Shots            int           `json:"shots"`
+	PenaltyShortfall bool          `json:"penaltyShortfall,omitempty"`
+}
+
+type jsonLap struct {
+	Lap         int     `json:"lap"`
+	Completed   bool    `json:"completed"`
+	Time        string  `json:"time,omitempty"`
+	Speed       float64 `json:"speed,omitempty"`
+	Finish      string  `json:"finish,omitempty"`
+	Rank        int     `json:"rank,omitempty"`
+	ToRange     string  `json:"toRange,omitempty"`
+	RangeTime   string  `json:"rangeTime,omitempty"`
+	PenaltyTime string  `json:"penaltyTime,omitempty"`
+	SkiTime     string  `json:"skiTime,omitempty"`
+}
+
+type jsonPenalty struct {
+	Start     string  `json:"start"`
+	Time      string  `json:"time"`
+	Speed     float64 `json:"speed"`
+	Loops     int     `json:"loops"`
+	Completed int     `json:"completed"`
+}
+
+type jsonFiring struct {
+	Line       int    `json:"line"`
+	TargetsHit []int  `json:"targetsHit"`
+	Misses     int    `json:"misses"`
+	Enter      string `json:"enter"`
+	Leave      string `json:"leave,omitempty"`
+}
+
+type jsonAnomaly struct {
+	Time    string `json:"time"`
+	Message string `json:"message"`
+}
+
+func (r *JSONReporter) OutputLog(w io.Writer, result model.ProcessResult) error {
+	events := make([]jsonEvent, len(result.OutputLog))
+	for i, event := range result.OutputLog {
+		events[i] = jsonEvent{
+			Time:         utils.FormatTimeRFC(event.Time),
+			EventID:      event.EventID,
+			Name:         getEventName(event.EventID),
+			CompetitorID: event.CompetitorID,
+			Params:       event.ExtraParams,
+			Description:  getEventDescription(event),
+		}
+	}
+	return writeJSON(w, events)
+}
+
+func (r *JSONReporter) OutputFinalReport(w io.Writer, result model.ProcessResult, cfg config.Config) error {
+	standings := computeStandings(result.Competitors)
+
+	report := jsonReport{Competitors: make([]jsonCompetitor, len(standings))}
+	for i, standing := range standings {
+		report.Competitors[i] = newJSONCompetitor(standing, cfg)
+	}
+	for _, anomaly := range result.Anomalies {
+		report.Anomalies = append(report.Anomalies, jsonAnomaly{
+			Time:    utils.FormatTimeRFC(anomaly.Time),
+			Message: anomaly.Err.Error(),
+		})
+	}
+
+	return writeJSON(w, report)
+}
+
+func newJSONCompetitor(standing Standing, cfg config.Config) jsonCompetitor {
+	comp := standing.Competitor
+	competitor := jsonCompetitor{
+		Place:            standing.Place,
+		ID:               comp.ID,
+		Status:           comp.Status,
+		Comment:          comp.StatusComment,
+		PlannedStart:     formatNonZeroTime(comp.PlannedStart),
+		ActualStart:      formatNonZeroTime(comp.ActualStart),
+		PenaltyTime:      formatPositiveDuration(comp.PenaltyTime()),
+		PenaltySpeed:     comp.PenaltySpeed(cfg.PenaltyLen),
+		Laps:             make([]jsonLap, len(comp.LapTimes)),
+		Penalties:        make([]jsonPenalty, len(comp.Penalties)),
+		Shooting:         make([]jsonFiring, len(comp.Firings)),
+		Hits:             comp.ShotsHit(),
+		Shots:            comp.TotalShots(),
+		PenaltyShortfall: comp.PenaltyShortfall,
+	}
+
+	if comp.IsFinished() {
+		competitor.TotalTime = utils.FormatDuration(comp.TotalTime())
+		competitor.Gap = formatPositiveDuration(standing.Gap)
+	}
+
+	for i, lap := range comp.LapTimes {
+		competitor.Laps[i] = jsonLap{Lap: i + 1, Completed: lap.Time > 0}
+		if lap.Time <= 0 {
+			continue
+		}
+		competitor.Laps[i].Time = utils.FormatDuration(lap.Time)
+		competitor.Laps[i].Speed = lap.Speed
+		competitor.Laps[i].Finish = utils.FormatTimeRFC(lap.Finish)
+		competitor.Laps[i].Rank = standing.LapRanks[i]
+		competitor.Laps[i].ToRange = formatPositiveDuration(lap.ToRange)
+		competitor.Laps[i].RangeTime = formatPositiveDuration(lap.RangeTime)
+		competitor.Laps[i].PenaltyTime = formatPositiveDuration(lap.PenaltyTime)
+		competitor.Laps[i].SkiTime = formatPositiveDuration(lap.SkiTime)
+	}
+
+	for i, penalty := range comp.Penalties {
+		competitor.Penalties[i] = jsonPenalty{
+			Start:     utils.FormatTimeRFC(penalty.StartTime),
+			Time:      utils.FormatDuration(penalty.Duration),
+			Speed:     penalty.Speed,
+			Loops:     penalty.Loops,
+			Completed: penalty.Completed,
+		}
+	}
+
+	for i, firing := range comp.Firings {
+		competitor.Shooting[i] = jsonFiring{
+			Line:       firing.Line,
+			TargetsHit: append([]int{}, firing.TargetsHit...),
+			Misses:     firing.Misses,
+			Enter:      utils.FormatTimeRFC(firing.EnterTime),
+			Leave:      formatNonZeroTime(firing.LeaveTime),
+		}
+	}
+
+	return competitor
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
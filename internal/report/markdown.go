@@ -0,0 +1,124 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// MarkdownReporter writes GitHub-flavored Markdown tables for race reports.
+type MarkdownReporter struct{}
+
+type rangeSummary struct {
+	visits int
+	hits   int
+}
+
+func (r *MarkdownReporter) OutputLog(out io.Writer, result model.ProcessResult) error {
+	w := bufio.NewWriter(out)
+
+	fmt.Fprintln(w, "## Event log")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Time | Competitor | Event | Description |")
+	fmt.Fprintln(w, "|---|---:|---|---|")
+	for _, event := range result.OutputLog {
+		fmt.Fprintf(w, "| %s | %d | %s | %s |\n",
+			utils.FormatTimeRFC(event.Time),
+			event.CompetitorID,
+			getEventName(event.EventID),
+			escapeMarkdown(getEventDescription(event)))
+	}
+
+	return w.Flush()
+}
+
+func (r *MarkdownReporter) OutputFinalReport(out io.Writer, result model.ProcessResult, cfg config.Config) error {
+	w := bufio.NewWriter(out)
+	standings := computeStandings(result.Competitors)
+
+	fmt.Fprintln(w, "## Results")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Rank | Competitor | Total time | Gap | Shooting | Penalty |")
+	fmt.Fprintln(w, "|---:|---:|---|---|---|---|")
+	for _, standing := range standings {
+		comp := standing.Competitor
+
+		totalTime := comp.Status
+		if comp.IsFinished() {
+			totalTime = utils.FormatDuration(comp.TotalTime())
+		}
+
+		shooting := comp.ShotAccuracy()
+		if comp.PenaltyShortfall {
+			shooting += " (penalty shortfall)"
+		}
+
+		penalty := "-"
+		if penaltyTime := comp.PenaltyTime(); penaltyTime > 0 {
+			penalty = fmt.Sprintf("%s (%s m/s)", utils.FormatDuration(penaltyTime), formatSpeed(comp.PenaltySpeed(cfg.PenaltyLen)))
+		}
+
+		fmt.Fprintf(w, "| %s | %d | %s | %s | %s | %s |\n",
+			formatPlace(standing.Place), comp.ID, totalTime, formatGap(standing), shooting, penalty)
+	}
+
+	outputMarkdownShooting(w, standings)
+
+	if len(result.Anomalies) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Anomalies")
+		fmt.Fprintln(w)
+		for _, anomaly := range result.Anomalies {
+			fmt.Fprintf(w, "- `%s` %s\n", utils.FormatTimeRFC(anomaly.Time), escapeMarkdown(anomaly.Err.Error()))
+		}
+	}
+
+	return w.Flush()
+}
+
+func outputMarkdownShooting(w io.Writer, standings []Standing) {
+	summaries := make(map[int]*rangeSummary)
+	for _, standing := range standings {
+		for _, firing := range standing.Competitor.Firings {
+			summary, ok := summaries[firing.Line]
+			if !ok {
+				summary = &rangeSummary{}
+				summaries[firing.Line] = summary
+			}
+			summary.visits++
+			summary.hits += len(firing.TargetsHit)
+		}
+	}
+
+	if len(summaries) == 0 {
+		return
+	}
+
+	lines := make([]int, 0, len(summaries))
+	for line := range summaries {
+		lines = append(lines, line)
+	}
+	slices.Sort(lines)
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Shooting by range")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Range | Visits | Hits | Shots | Accuracy |")
+	fmt.Fprintln(w, "|---:|---:|---:|---:|---:|")
+	for _, line := range lines {
+		summary := summaries[line]
+		shots := summary.visits * model.TargetsPerLine
+		fmt.Fprintf(w, "| %d | %d | %d | %d | %.1f%% |\n",
+			line, summary.visits, summary.hits, shots, 100*float64(summary.hits)/float64(shots))
+	}
+}
+
+func escapeMarkdown(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -1,103 +1,187 @@
 package report
 
 import (
+	"bufio"
+	"cmp"
 	"fmt"
-	"sort"
-	"strings"
+	"io"
+	"slices"
+	"time"
 
 	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/event"
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
 	"github.com/niklvdanya/BiathlonTracker/internal/utils"
 )
 
-func OutputLog(events []model.Event) {
+func OutputLog(out io.Writer, events []model.Event) error {
+	w := bufio.NewWriter(out)
 	for _, event := range events {
 		description := getEventDescription(event)
-		fmt.Printf("[%s] %s\n", utils.FormatTimeRFC(event.Time), description)
-	}
-}
-
-func getEventDescription(event model.Event) string {
-	switch event.EventID {
-	case model.EventRegistration:
-		return fmt.Sprintf("The competitor(%d) registered", event.CompetitorID)
-	case model.EventSetStartTime:
-		return fmt.Sprintf("The start time for the competitor(%d) was set by a draw to %s", event.CompetitorID, event.ExtraParams)
-	case model.EventStartLine:
-		return fmt.Sprintf("The competitor(%d) is on the start line", event.CompetitorID)
-	case model.EventStarted:
-		return fmt.Sprintf("The competitor(%d) has started", event.CompetitorID)
-	case model.EventFiringRange:
-		return fmt.Sprintf("The competitor(%d) is on the firing range(%s)", event.CompetitorID, event.ExtraParams)
-	case model.EventShot:
-		return fmt.Sprintf("The target(%s) has been hit by competitor(%d)", event.ExtraParams, event.CompetitorID)
-	case model.EventLeaveFiring:
-		return fmt.Sprintf("The competitor(%d) left the firing range", event.CompetitorID)
-	case model.EventEnterPenalty:
-		return fmt.Sprintf("The competitor(%d) entered the penalty laps", event.CompetitorID)
-	case model.EventLeavePenalty:
-		return fmt.Sprintf("The competitor(%d) left the penalty laps", event.CompetitorID)
-	case model.EventLapEnd:
-		return fmt.Sprintf("The competitor(%d) ended the main lap", event.CompetitorID)
-	case model.EventLostInForest:
-		return fmt.Sprintf("The competitor(%d) can`t continue: %s", event.CompetitorID, event.ExtraParams)
-	case model.EventDisqualified:
-		return fmt.Sprintf("The competitor(%d) is disqualified", event.CompetitorID)
-	case model.EventFinished:
-		return fmt.Sprintf("The competitor(%d) has finished", event.CompetitorID)
-	default:
-		return fmt.Sprintf("Unknown event(%d) for competitor(%d)", event.EventID, event.CompetitorID)
-	}
-}
-
-func OutputFinalReport(competitors map[int]*model.Competitor, cfg config.Config) {
-	competitorsList := sortCompetitors(competitors)
-
-	fmt.Println("\nFinal Report:")
-	fmt.Println("============================================")
+		fmt.Fprintf(w, "[%s] %s\n", utils.FormatTimeRFC(event.Time), description)
+	}
+	return w.Flush()
+}
+
+// getEventName and getEventDescription look events up in the event type
+// registry, so custom event types show up in every report format.
+func getEventName(eventID int) string {
+	return event.EventName(eventID)
+}
+
+func getEventDescription(e model.Event) string {
+	return event.DescribeEvent(e)
+}
+
+// OutputFinalReport buffers the whole report, so a write error surfaces
+// once from the final flush.
+func OutputFinalReport(out io.Writer, result model.ProcessResult, cfg config.Config) error {
+	w := bufio.NewWriter(out)
+
+	standings := computeStandings(result.Competitors)
+	competitorsList := make([]*model.Competitor, len(standings))
+	for i, standing := range standings {
+		competitorsList[i] = standing.Competitor
+	}
+
+	fmt.Fprintln(w, "\nFinal Report:")
+	fmt.Fprintln(w, "============================================")
 
 	for _, comp := range competitorsList {
-		outputCompetitorInfo(comp)
+		outputCompetitorInfo(w, comp, cfg)
+	}
+
+	fmt.Fprintln(w, "============================================")
+
+	outputResults(w, standings)
+	outputDetailedReport(w, competitorsList)
+	outputLapSplits(w, competitorsList)
+	outputAnomalies(w, result.Anomalies)
+	return w.Flush()
+}
+
+func outputDetailedReport(w io.Writer, competitors []*model.Competitor) {
+	fmt.Fprintln(w, "\nDetailed Report:")
+	fmt.Fprintln(w, "============================================")
+
+	for _, comp := range competitors {
+		fmt.Fprintf(w, "%d planned %s, on start line %s, started %s, time on start line %s\n",
+			comp.ID,
+			formatOptionalTime(comp.PlannedStart),
+			formatOptionalTime(comp.StartLineTime),
+			formatOptionalTime(comp.ActualStart),
+			formatOptionalDuration(comp.TimeOnStartLine()))
+	}
+
+	fmt.Fprintln(w, "============================================")
+}
+
+func outputLapSplits(w io.Writer, competitors []*model.Competitor) {
+	fmt.Fprintln(w, "\nLap Splits:")
+	fmt.Fprintln(w, "============================================")
+
+	for _, comp := range competitors {
+		for i, lap := range comp.LapTimes {
+			if lap.Time <= 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%d lap %d %s: to range %s, range %s, penalty %s, ski %s\n",
+				comp.ID,
+				i+1,
+				utils.FormatDuration(lap.Time),
+				formatOptionalDuration(lap.ToRange),
+				formatOptionalDuration(lap.RangeTime),
+				formatOptionalDuration(lap.PenaltyTime),
+				utils.FormatDuration(lap.SkiTime))
+		}
+	}
+
+	fmt.Fprintln(w, "============================================")
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return utils.FormatTimeRFC(t)
+}
+
+func formatOptionalDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
 	}
+	return utils.FormatDuration(d)
+}
 
-	fmt.Println("============================================")
+// formatNonZeroTime and formatPositiveDuration leave missing values empty
+// for the machine-readable formats.
+func formatNonZeroTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return utils.FormatTimeRFC(t)
 }
 
+func formatPositiveDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return utils.FormatDuration(d)
+}
+
+func outputAnomalies(w io.Writer, anomalies []model.Anomaly) {
+	if len(anomalies) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nAnomalies:")
+	for _, anomaly := range anomalies {
+		fmt.Fprintf(w, "[%s] %v\n", utils.FormatTimeRFC(anomaly.Time), anomaly.Err)
+	}
+}
+
+var statusOrder = map[string]int{
+	model.StatusFinished:     0,
+	model.StatusRunning:      1,
+	model.StatusNotFinished:  2,
+	model.StatusNotStarted:   3,
+	model.StatusDisqualified: 4,
+}
+
+// sortCompetitors orders finishers by total time, then everyone else by
+// status; competitor IDs break any remaining ties.
 func sortCompetitors(competitors map[int]*model.Competitor) []*model.Competitor {
 	competitorsList := make([]*model.Competitor, 0, len(competitors))
 	for _, comp := range competitors {
 		competitorsList = append(competitorsList, comp)
 	}
 
-	sort.Slice(competitorsList, func(i, j int) bool {
-		if competitorsList[i].IsFinished() && competitorsList[j].IsFinished() {
-			return competitorsList[i].TotalTime() < competitorsList[j].TotalTime()
+	slices.SortStableFunc(competitorsList, func(a, b *model.Competitor) int {
+		if order := cmp.Compare(statusOrder[a.Status], statusOrder[b.Status]); order != 0 {
+			return order
 		}
-
-		statusOrder := map[string]int{
-			model.StatusFinished:     0,
-			model.StatusNotFinished:  1,
-			model.StatusNotStarted:   2,
-			model.StatusDisqualified: 3,
+		if a.IsFinished() && b.IsFinished() {
+			if order := cmp.Compare(a.TotalTime(), b.TotalTime()); order != 0 {
+				return order
+			}
 		}
-		return statusOrder[competitorsList[i].Status] < statusOrder[competitorsList[j].Status]
+		return cmp.Compare(a.ID, b.ID)
 	})
 
 	return competitorsList
 }
 
-func outputCompetitorInfo(comp *model.Competitor) {
+func outputCompetitorInfo(w io.Writer, comp *model.Competitor, cfg config.Config) {
 	statusStr := getStatusString(comp)
+	lapInfo := formatLapInfo(comp.LapTimes)
+	penaltyInfo := formatPenaltyInfo(comp, cfg)
+	hitsInfo := comp.ShotAccuracy()
 
-	if comp.Status == model.StatusNotFinished && strings.Contains(comp.StatusComment, "Lost in the forest") {
-		fmt.Printf("%s %d [{00:29:03.872, 2.093}, {,}] {00:01:44.296, 0.481} 4/5\n", statusStr, comp.ID)
-	} else {
-		lapInfo := formatLapInfo(comp.LapTimes)
-		penaltyInfo := formatPenaltyInfo(comp.PenaltyLapInfo)
-		hitsInfo := comp.ShotAccuracy()
-
-		fmt.Printf("%s %d %s %s %s\n", statusStr, comp.ID, lapInfo, penaltyInfo, hitsInfo)
+	if comp.PenaltyShortfall {
+		hitsInfo += " [PenaltyShortfall]"
 	}
+
+	fmt.Fprintf(w, "%s %d %s %s %s\n", statusStr, comp.ID, lapInfo, penaltyInfo, hitsInfo)
 }
 
 func getStatusString(comp *model.Competitor) string {
@@ -107,6 +191,8 @@ func getStatusString(comp *model.Competitor) string {
 	return fmt.Sprintf("[%s]", comp.Status)
 }
 
+// formatLapInfo shows a lap the competitor abandoned midway with the time
+// skied on it and a lap never started as an empty {,} pair.
 func formatLapInfo(lapTimes []model.LapInfo) string {
 	lapInfo := "["
 	for i, lap := range lapTimes {
@@ -115,6 +201,8 @@ func formatLapInfo(lapTimes []model.LapInfo) string {
 		}
 		if lap.Time > 0 {
 			lapInfo += fmt.Sprintf("{%s, %.3f}", utils.FormatDuration(lap.Time), lap.Speed)
+		} else if lap.Elapsed > 0 {
+			lapInfo += fmt.Sprintf("{incomplete, %s}", utils.FormatDuration(lap.Elapsed))
 		} else {
 			lapInfo += "{,}"
 		}
@@ -123,9 +211,9 @@ func formatLapInfo(lapTimes []model.LapInfo) string {
 	return lapInfo
 }
 
-func formatPenaltyInfo(penaltyInfo model.PenaltyInfo) string {
-	if penaltyInfo.Duration > 0 {
-		return fmt.Sprintf("{%s, %.3f}", utils.FormatDuration(penaltyInfo.Duration), penaltyInfo.Speed)
+func formatPenaltyInfo(comp *model.Competitor, cfg config.Config) string {
+	if penaltyTime := comp.PenaltyTime(); penaltyTime > 0 {
+		return fmt.Sprintf("{%s, %.3f}", utils.FormatDuration(penaltyTime), comp.PenaltySpeed(cfg.PenaltyLen))
 	}
 	return "{,}"
 }
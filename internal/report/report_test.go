@@ -2,13 +2,18 @@ package report
 
 import (
 	"bytes"
-	"io"
+	"context"
+	"encoding/json"
+	"encoding/xml"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/event"
 	"github.com/niklvdanya/BiathlonTracker/internal/model"
 )
 
@@ -33,17 +38,10 @@ func TestOutputLog(t *testing.T) {
 		},
 	}
 
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	OutputLog(events)
-
-	w.Close()
-	os.Stdout = oldStdout
-
 	var buf bytes.Buffer
-	io.Copy(&buf, r)
+	if err := OutputLog(&buf, events); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	output := buf.String()
 
 	expectedStrings := []string{
@@ -82,8 +80,9 @@ func TestOutputFinalReport(t *testing.T) {
 					Finish: startTime.Add(29 * time.Minute),
 				},
 			},
-			ShotsHit:   4,
-			TotalShots: 5,
+			Firings: []model.FiringRecord{
+				{Line: 1, TargetsHit: []int{1, 2, 3, 4}, Misses: 1},
+			},
 		},
 		2: {
 			ID:            2,
@@ -98,8 +97,9 @@ func TestOutputFinalReport(t *testing.T) {
 					Finish: startTime.Add(16*time.Minute + 30*time.Second),
 				},
 			},
-			ShotsHit:   4,
-			TotalShots: 5,
+			Firings: []model.FiringRecord{
+				{Line: 1, TargetsHit: []int{1, 2, 4, 5}, Misses: 1},
+			},
 		},
 		3: {
 			ID:          3,
@@ -114,21 +114,16 @@ func TestOutputFinalReport(t *testing.T) {
 		PenaltyLen: 150,
 	}
 
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	OutputFinalReport(competitors, cfg)
-
-	w.Close()
-	os.Stdout = oldStdout
-
 	var buf bytes.Buffer
-	io.Copy(&buf, r)
+	if err := OutputFinalReport(&buf, model.ProcessResult{Competitors: competitors}, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	output := buf.String()
 
 	expectedStrings := []string{
 		"Final Report",
+		"Detailed Report",
+		"Lap Splits",
 		"00:29:00.000",
 		"[NotFinished]",
 		"[Disqualified]",
@@ -172,3 +167,549 @@ func TestSortingCompetitors(t *testing.T) {
 		t.Errorf("Expected fourth competitor to be Disqualified, got %s", sorted[3].Status)
 	}
 }
+
+func TestPenaltyInfoAccumulatesStints(t *testing.T) {
+	comp := &model.Competitor{
+		ID:     1,
+		Status: model.StatusFinished,
+		Firings: []model.FiringRecord{
+			{Line: 1, TargetsHit: []int{1, 2, 3, 4}, Misses: 1},
+			{Line: 2, TargetsHit: []int{1, 2, 3}, Misses: 2},
+		},
+		Penalties: []model.PenaltyInfo{
+			{Duration: 30 * time.Second, Loops: 1},
+			{Duration: 60 * time.Second, Loops: 2},
+		},
+	}
+
+	result := formatPenaltyInfo(comp, config.Config{PenaltyLen: 150})
+	if result != "{00:01:30.000, 5.000}" {
+		t.Errorf("expected {00:01:30.000, 5.000}, got %s", result)
+	}
+}
+
+func TestComputeStandings(t *testing.T) {
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: model.StatusFinished, LapTimes: []model.LapInfo{{Time: 14 * time.Minute}, {Time: 16 * time.Minute}}},
+		2: {ID: 2, Status: model.StatusFinished, LapTimes: []model.LapInfo{{Time: 13 * time.Minute}, {Time: 12 * time.Minute}}},
+		3: {ID: 3, Status: model.StatusFinished, LapTimes: []model.LapInfo{{Time: 15 * time.Minute}, {Time: 15 * time.Minute}}},
+		4: {ID: 4, Status: model.StatusNotFinished, LapTimes: []model.LapInfo{{Time: 12 * time.Minute}, {}}},
+		5: {ID: 5, Status: model.StatusFinished, LapTimes: []model.LapInfo{{Time: 10 * time.Minute}, {Time: 15 * time.Minute}}},
+		6: {ID: 6, Status: model.StatusDisqualified, LapTimes: []model.LapInfo{{Time: 9 * time.Minute}, {Time: 9 * time.Minute}}},
+	}
+
+	standings := computeStandings(competitors)
+
+	expected := []struct {
+		id       int
+		place    int
+		gap      time.Duration
+		lapRanks []int
+	}{
+		{id: 2, place: 1, gap: 0, lapRanks: []int{2, 1}},
+		{id: 5, place: 1, gap: 0, lapRanks: []int{1, 2}},
+		{id: 1, place: 3, gap: 5 * time.Minute, lapRanks: []int{3, 4}},
+		{id: 3, place: 3, gap: 5 * time.Minute, lapRanks: []int{4, 2}},
+		{id: 4, place: 0, gap: 0, lapRanks: []int{0, 0}},
+		{id: 6, place: 0, gap: 0, lapRanks: []int{0, 0}},
+	}
+
+	if len(standings) != len(expected) {
+		t.Fatalf("expected %d standings, got %d", len(expected), len(standings))
+	}
+
+	for i, want := range expected {
+		got := standings[i]
+		if got.Competitor.ID != want.id || got.Place != want.place || got.Gap != want.gap ||
+			!slices.Equal(got.LapRanks, want.lapRanks) {
+			t.Errorf("standing %d: expected %+v, got id %d place %d gap %v lap ranks %v",
+				i, want, got.Competitor.ID, got.Place, got.Gap, got.LapRanks)
+		}
+	}
+
+	if formatGap(standings[2]) != "+00:05:00.000" {
+		t.Errorf("expected gap +00:05:00.000, got %s", formatGap(standings[2]))
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	startTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	result := model.ProcessResult{
+		Competitors: map[int]*model.Competitor{
+			1: {
+				ID:          1,
+				Status:      model.StatusFinished,
+				ActualStart: startTime,
+				LapTimes: []model.LapInfo{
+					{Time: 15 * time.Minute, Speed: 3.89, Finish: startTime.Add(15 * time.Minute)},
+				},
+				Firings: []model.FiringRecord{
+					{Line: 1, TargetsHit: []int{1, 2, 3, 4}, Misses: 1, EnterTime: startTime.Add(5 * time.Minute)},
+				},
+				Penalties: []model.PenaltyInfo{
+					{StartTime: startTime.Add(6 * time.Minute), Duration: 30 * time.Second, Loops: 1, Completed: 1},
+				},
+			},
+			2: {ID: 2, Status: model.StatusNotFinished, LapTimes: []model.LapInfo{{}}},
+		},
+		OutputLog: []model.Event{
+			{Time: startTime, EventID: model.EventStarted, CompetitorID: 1},
+		},
+	}
+	cfg := config.Config{Laps: 1, LapLen: 3500, PenaltyLen: 150}
+
+	reporter := &JSONReporter{}
+
+	var logBuf bytes.Buffer
+	if err := reporter.OutputLog(&logBuf, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var events []jsonEvent
+	if err := json.Unmarshal(logBuf.Bytes(), &events); err != nil {
+		t.Fatalf("invalid log JSON: %v", err)
+	}
+	if len(events) != 1 || events[0].Name != "Started" || events[0].Time != "10:00:00.000" {
+		t.Errorf("unexpected log: %+v", events)
+	}
+
+	var reportBuf bytes.Buffer
+	if err := reporter.OutputFinalReport(&reportBuf, result, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(reportBuf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if len(report.Competitors) != 2 {
+		t.Fatalf("expected 2 competitors, got %d", len(report.Competitors))
+	}
+
+	winner := report.Competitors[0]
+	if winner.ID != 1 || winner.Place != 1 || winner.TotalTime != "00:15:00.000" ||
+		winner.PenaltyTime != "00:00:30.000" || winner.Hits != 4 || winner.Shots != 5 {
+		t.Errorf("unexpected winner: %+v", winner)
+	}
+	if len(winner.Shooting) != 1 || winner.Shooting[0].Misses != 1 || len(winner.Penalties) != 1 {
+		t.Errorf("unexpected winner shooting or penalties: %+v", winner)
+	}
+
+	lost := report.Competitors[1]
+	if lost.Place != 0 || lost.Status != model.StatusNotFinished || len(lost.Laps) != 1 || lost.Laps[0].Completed {
+		t.Errorf("unexpected not finished competitor: %+v", lost)
+	}
+}
+
+func TestCSVReporter(t *testing.T) {
+	startTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	result := model.ProcessResult{
+		Competitors: map[int]*model.Competitor{
+			1: {
+				ID:     1,
+				Status: model.StatusFinished,
+				LapTimes: []model.LapInfo{
+					{Time: 15 * time.Minute, Speed: 3.889, Finish: startTime.Add(15 * time.Minute)},
+					{Time: 14 * time.Minute, Speed: 4.167, Finish: startTime.Add(29 * time.Minute)},
+				},
+				Firings:   []model.FiringRecord{{Line: 1, TargetsHit: []int{1, 2, 3, 4}, Misses: 1}},
+				Penalties: []model.PenaltyInfo{{Duration: 30 * time.Second, Loops: 1}},
+			},
+			2: {
+				ID:       2,
+				Status:   model.StatusNotFinished,
+				LapTimes: []model.LapInfo{{Time: 16 * time.Minute, Speed: 3.646, Finish: startTime.Add(16 * time.Minute)}, {}},
+			},
+		},
+	}
+	cfg := config.Config{Laps: 2, LapLen: 3500, PenaltyLen: 150}
+
+	reporter := &CSVReporter{}
+
+	var results bytes.Buffer
+	if err := reporter.OutputFinalReport(&results, result, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedResults := "place,competitor,status,total_time,gap,hits,shots,penalty_time,penalty_speed\n" +
+		"1,1,Finished,00:29:00.000,,4,5,00:00:30.000,5.000\n" +
+		",2,NotFinished,,,0,0,,\n"
+	if results.String() != expectedResults {
+		t.Errorf("expected results:\n%s\ngot:\n%s", expectedResults, results.String())
+	}
+
+	var laps bytes.Buffer
+	if err := reporter.OutputLaps(&laps, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedLaps := "competitor,lap,lap_time,speed,finish_time\n" +
+		"1,1,00:15:00.000,3.889,10:15:00.000\n" +
+		"1,2,00:14:00.000,4.167,10:29:00.000\n" +
+		"2,1,00:16:00.000,3.646,10:16:00.000\n" +
+		"2,2,,,\n"
+	if laps.String() != expectedLaps {
+		t.Errorf("expected laps:\n%s\ngot:\n%s", expectedLaps, laps.String())
+	}
+}
+
+func TestHTMLReporter(t *testing.T) {
+	startTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	result := model.ProcessResult{
+		Competitors: map[int]*model.Competitor{
+			1: {
+				ID:       1,
+				Status:   model.StatusFinished,
+				LapTimes: []model.LapInfo{{Time: 15 * time.Minute, Speed: 3.889, Finish: startTime.Add(15 * time.Minute)}},
+			},
+			2: {ID: 2, Status: model.StatusDisqualified, LapTimes: []model.LapInfo{{}}},
+		},
+		OutputLog: []model.Event{
+			{Time: startTime, EventID: model.EventLostInForest, CompetitorID: 2, ExtraParams: "<script>alert(1)</script>"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&HTMLReporter{}).OutputFinalReport(&buf, result, config.Config{Laps: 1, Date: "2025-01-01"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	expectedStrings := []string{
+		"<title>Results 2025-01-01</title>",
+		`class="status-finished">Finished`,
+		`class="status-disqualified">Disqualified`,
+		"00:15:00.000",
+		"<details>",
+		"Event log",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain '%s', got: %s", expected, output)
+		}
+	}
+
+	if strings.Index(output, `class="status-finished"`) > strings.Index(output, `class="status-disqualified"`) {
+		t.Errorf("expected finished competitors before disqualified ones")
+	}
+
+	buf.Reset()
+	if err := (&HTMLReporter{}).OutputLog(&buf, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logPage := buf.String(); strings.Contains(logPage, "<h2>Results</h2>") || !strings.Contains(logPage, "<h2>Event log</h2>") {
+		t.Errorf("expected the log page to hold only the event log, got: %s", logPage)
+	}
+}
+
+func TestMarkdownReporter(t *testing.T) {
+	result := model.ProcessResult{
+		Competitors: map[int]*model.Competitor{
+			1: {
+				ID:       1,
+				Status:   model.StatusFinished,
+				LapTimes: []model.LapInfo{{Time: 30 * time.Minute}},
+				Firings: []model.FiringRecord{
+					{Line: 1, TargetsHit: []int{1, 2, 3, 4}, Misses: 1},
+					{Line: 2, TargetsHit: []int{1, 2, 3, 4, 5}},
+				},
+				Penalties: []model.PenaltyInfo{{Duration: 30 * time.Second, Loops: 1}},
+			},
+			2: {
+				ID:       2,
+				Status:   model.StatusFinished,
+				LapTimes: []model.LapInfo{{Time: 31 * time.Minute}},
+				Firings: []model.FiringRecord{
+					{Line: 1, TargetsHit: []int{1, 2}, Misses: 3},
+				},
+			},
+			3: {ID: 3, Status: model.StatusNotStarted},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&MarkdownReporter{}).OutputFinalReport(&buf, result, config.Config{PenaltyLen: 150}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	expectedStrings := []string{
+		"| 1 | 1 | 00:30:00.000 | - | 9/10 | 00:00:30.000 (5.000 m/s) |",
+		"| 2 | 2 | 00:31:00.000 | +00:01:00.000 | 2/5 | - |",
+		"| - | 3 | NotStarted | - | 0/0 | - |",
+		"| 1 | 2 | 6 | 10 | 60.0% |",
+		"| 2 | 1 | 5 | 5 | 100.0% |",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain '%s', got: %s", expected, output)
+		}
+	}
+}
+
+func TestXMLReporterMatchesSample(t *testing.T) {
+	startTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	result := model.ProcessResult{
+		Competitors: map[int]*model.Competitor{
+			1: {
+				ID:     1,
+				Status: model.StatusFinished,
+				LapTimes: []model.LapInfo{
+					{
+						Time: 15 * time.Minute, Speed: 3.889, Finish: startTime.Add(15 * time.Minute),
+						ToRange: 8 * time.Minute, RangeTime: time.Minute, PenaltyTime: 30 * time.Second, SkiTime: 13*time.Minute + 30*time.Second,
+					},
+					{
+						Time: 14 * time.Minute, Speed: 4.167, Finish: startTime.Add(29 * time.Minute),
+						ToRange: 7 * time.Minute, RangeTime: time.Minute, SkiTime: 13 * time.Minute,
+					},
+				},
+				Firings: []model.FiringRecord{
+					{Line: 1, TargetsHit: []int{1, 2, 3, 4}, Misses: 1},
+					{Line: 2, TargetsHit: []int{1, 2, 3, 4, 5}},
+				},
+				Penalties: []model.PenaltyInfo{{Duration: 30 * time.Second, Loops: 1, Completed: 1}},
+			},
+			2: {
+				ID:     2,
+				Status: model.StatusFinished,
+				LapTimes: []model.LapInfo{
+					{Time: 16 * time.Minute, Speed: 3.646, Finish: startTime.Add(16 * time.Minute)},
+					{Time: 14 * time.Minute, Speed: 4.167, Finish: startTime.Add(30 * time.Minute)},
+				},
+				Firings: []model.FiringRecord{
+					{Line: 1, TargetsHit: []int{1, 2, 3, 4, 5}},
+					{Line: 2, TargetsHit: []int{1, 2, 3, 4, 5}},
+				},
+			},
+			3: {
+				ID:            3,
+				Status:        model.StatusNotFinished,
+				StatusComment: "Lost in the forest",
+				LapTimes:      []model.LapInfo{{}, {}},
+			},
+			4: {ID: 4, Status: model.StatusNotStarted, LapTimes: []model.LapInfo{{}, {}}},
+			5: {ID: 5, Status: model.StatusDisqualified, LapTimes: []model.LapInfo{{}, {}}},
+		},
+	}
+	cfg := config.Config{
+		Date:        "2025-01-01",
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+	}
+
+	sample, err := os.ReadFile("testdata/results.xml")
+	if err != nil {
+		t.Fatalf("failed to read sample: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := (&XMLReporter{}).OutputFinalReport(&buf, result, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != string(sample) {
+		t.Errorf("report differs from sample:\n%s", buf.String())
+	}
+
+	var decoded xmlResults
+	if err := xml.Unmarshal(sample, &decoded); err != nil {
+		t.Fatalf("failed to decode sample: %v", err)
+	}
+
+	statuses := make([]string, len(decoded.Competitors))
+	for i, competitor := range decoded.Competitors {
+		statuses[i] = competitor.Status
+	}
+	if !slices.Equal(statuses, []string{"OK", "OK", "DNF", "DNS", "DSQ"}) {
+		t.Errorf("unexpected status codes: %v", statuses)
+	}
+	if decoded.Competitors[0].Shooting.Stages != "1 0" || decoded.Competitors[1].Behind != "00:01:00.000" {
+		t.Errorf("unexpected decoded results: %+v", decoded.Competitors[:2])
+	}
+
+	var reencoded bytes.Buffer
+	if err := writeXML(&reencoded, decoded); err != nil {
+		t.Fatalf("failed to encode decoded sample: %v", err)
+	}
+	if reencoded.String() != string(sample) {
+		t.Errorf("sample does not survive a round trip:\n%s", reencoded.String())
+	}
+}
+
+const raceEvents = `[09:05:59.867] 1 1
+[09:15:00.841] 2 1 09:30:00.000
+[09:29:45.734] 3 1
+[09:30:01.005] 4 1
+[09:49:31.659] 5 1 1
+[09:49:33.123] 6 1 1
+[09:49:34.650] 6 1 2
+[09:49:35.937] 6 1 4
+[09:49:37.364] 6 1 5
+[09:49:38.339] 7 1
+[09:49:55.915] 8 1
+[09:51:48.391] 9 1
+[09:59:03.872] 10 1
+[09:59:03.872] 11 1 Lost in the forest
+[09:06:10.000] 1 2
+[09:06:20.000] 1 3
+[09:06:30.000] 1 4
+[09:15:10.000] 2 2 09:31:00.000
+[09:15:20.000] 2 3 09:32:00.000
+[09:15:30.000] 2 4 09:33:00.000
+[09:30:55.000] 3 2
+[09:31:00.000] 4 2
+[09:35:00.000] 4 3
+[09:50:00.000] 5 2 1
+[09:50:01.000] 6 2 1
+[09:50:02.000] 6 2 2
+[09:50:03.000] 6 2 3
+[09:50:04.000] 7 2
+[10:00:00.000] 10 2
+[10:10:00.000] 5 2 2
+[10:10:01.000] 6 2 1
+[10:10:02.000] 6 2 2
+[10:10:03.000] 6 2 3
+[10:10:04.000] 6 2 4
+[10:10:05.000] 6 2 5
+[10:10:06.000] 7 2
+[10:20:00.000] 10 2
+`
+
+func TestParallelMatchesSequential(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "09:30:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	parseOptions := event.ParseOptions{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	sequentialEvents, _, err := event.ReadEvents(strings.NewReader(raceEvents), parseOptions)
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+	parallelEvents, _, err := event.ReadEvents(strings.NewReader(raceEvents), parseOptions)
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+
+	sequential := event.ProcessEvents(ctx, sequentialEvents, cfg)
+	parallel := event.ProcessEventsParallel(ctx, parallelEvents, cfg)
+
+	if !reflect.DeepEqual(sequential.OutputLog, parallel.OutputLog) {
+		t.Errorf("output logs differ:\nsequential: %v\nparallel:   %v", sequential.OutputLog, parallel.OutputLog)
+	}
+
+	if sequential.Stats != parallel.Stats {
+		t.Errorf("stats differ: sequential %+v, parallel %+v", sequential.Stats, parallel.Stats)
+	}
+
+	var generated []int
+	for _, logged := range parallel.OutputLog {
+		if logged.EventID >= model.EventDisqualified {
+			generated = append(generated, logged.EventID)
+		}
+	}
+	expectedGenerated := []int{model.EventNotStarted, model.EventDisqualified, model.EventPenaltyShortfall, model.EventFinished}
+	if !reflect.DeepEqual(generated, expectedGenerated) {
+		t.Errorf("expected generated events %v, got %v", expectedGenerated, generated)
+	}
+
+	sequentialReport := captureReport(t, sequential, cfg)
+	parallelReport := captureReport(t, parallel, cfg)
+	if !bytes.Equal(sequentialReport, parallelReport) {
+		t.Errorf("reports differ:\nsequential:\n%s\nparallel:\n%s", sequentialReport, parallelReport)
+	}
+}
+
+func captureReport(t *testing.T, result model.ProcessResult, cfg config.Config) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := OutputLog(&buf, result.OutputLog); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	if err := OutputFinalReport(&buf, result, cfg); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestLostCompetitorsReport(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       "09:30:00.000",
+		StartDelta:  "00:01:30",
+		Date:        "2025-01-01",
+	}
+
+	input := `[09:00:00.000] 1 1
+[09:00:00.000] 1 2
+[09:00:00.000] 1 3
+[09:15:00.000] 2 1 09:30:00.000
+[09:15:00.000] 2 2 09:31:00.000
+[09:15:00.000] 2 3 09:32:00.000
+[09:30:01.000] 4 1
+[09:31:01.000] 4 2
+[09:32:01.000] 4 3
+[09:40:00.000] 11 1 Lost in the forest
+[09:49:31.000] 5 2 1
+[09:49:32.000] 6 2 1
+[09:49:33.000] 6 2 2
+[09:49:34.000] 6 2 4
+[09:49:35.000] 6 2 5
+[09:49:36.000] 7 2
+[09:49:40.000] 8 2
+[09:50:10.000] 9 2
+[09:55:00.000] 11 2 Lost in the forest
+[09:50:00.000] 5 3 1
+[09:50:01.000] 6 3 1
+[09:50:02.000] 6 3 2
+[09:50:03.000] 6 3 3
+[09:50:04.000] 6 3 4
+[09:50:05.000] 6 3 5
+[09:50:06.000] 7 3
+[09:59:01.000] 10 3
+[10:05:00.000] 11 3 Lost in the forest
+`
+
+	events, _, err := event.ReadEvents(strings.NewReader(input), event.ParseOptions{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+
+	result := event.ProcessEvents(ctx, events, cfg)
+	output := string(captureReport(t, result, cfg))
+
+	expectedLines := []string{
+		"[NotFinished] 1 [{incomplete, 00:09:59.000}, {,}] {,} 0/0",
+		"[NotFinished] 2 [{incomplete, 00:23:59.000}, {,}] {00:00:30.000, 5.000} 4/5",
+		"[NotFinished] 3 [{00:27:00.000, 2.160}, {incomplete, 00:05:59.000}] {,} 5/5",
+	}
+
+	for _, expected := range expectedLines {
+		if !strings.Contains(output, expected+"\n") {
+			t.Errorf("expected report line %q, got:\n%s", expected, output)
+		}
+	}
+}
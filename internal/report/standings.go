@@ -0,0 +1,114 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// Standing is a competitor's position in the results. Place and LapRanks
+// are zero when the competitor has no time to be ranked by; competitors
+// with identical times share a place. Only finishers are ranked, per lap as
+// well as overall, so a lap skied by a competitor who did not finish or was
+// disqualified never moves a finisher down.
+type Standing struct {
+	Competitor *model.Competitor
+	Place      int
+	Gap        time.Duration
+	LapRanks   []int
+}
+
+func computeStandings(competitors map[int]*model.Competitor) []Standing {
+	sorted := sortCompetitors(competitors)
+	standings := make([]Standing, len(sorted))
+
+	var leader time.Duration
+	for i, comp := range sorted {
+		standings[i] = Standing{Competitor: comp, LapRanks: make([]int, len(comp.LapTimes))}
+		if !comp.IsFinished() {
+			continue
+		}
+
+		totalTime := comp.TotalTime()
+		switch {
+		case i == 0:
+			standings[i].Place = 1
+			leader = totalTime
+		case totalTime == sorted[i-1].TotalTime():
+			standings[i].Place = standings[i-1].Place
+		default:
+			standings[i].Place = i + 1
+		}
+		standings[i].Gap = totalTime - leader
+	}
+
+	for i := range standings {
+		if standings[i].Place == 0 {
+			continue
+		}
+		for lap, info := range standings[i].Competitor.LapTimes {
+			if info.Time > 0 {
+				standings[i].LapRanks[lap] = lapRank(sorted, lap, info.Time)
+			}
+		}
+	}
+
+	return standings
+}
+
+func lapRank(competitors []*model.Competitor, lap int, lapTime time.Duration) int {
+	rank := 1
+	for _, comp := range competitors {
+		if !comp.IsFinished() {
+			continue
+		}
+		if lap < len(comp.LapTimes) && comp.LapTimes[lap].Time > 0 && comp.LapTimes[lap].Time < lapTime {
+			rank++
+		}
+	}
+	return rank
+}
+
+func outputResults(w io.Writer, standings []Standing) {
+	fmt.Fprintln(w, "\nResults:")
+	fmt.Fprintln(w, "============================================")
+	fmt.Fprintf(w, "%-5s %-4s %-14s %-14s %s\n", "Place", "ID", "Time", "Gap", "Lap ranks")
+
+	for _, standing := range standings {
+		fmt.Fprintf(w, "%-5s %-4d %-14s %-14s %s\n",
+			formatPlace(standing.Place),
+			standing.Competitor.ID,
+			getStatusString(standing.Competitor),
+			formatGap(standing),
+			formatLapRanks(standing.LapRanks))
+	}
+
+	fmt.Fprintln(w, "============================================")
+}
+
+func formatPlace(place int) string {
+	if place == 0 {
+		return "-"
+	}
+	return strconv.Itoa(place)
+}
+
+func formatGap(standing Standing) string {
+	if standing.Place == 0 || standing.Gap == 0 {
+		return "-"
+	}
+	return "+" + utils.FormatDuration(standing.Gap)
+}
+
+func formatLapRanks(ranks []int) string {
+	formatted := make([]string, len(ranks))
+	for i, rank := range ranks {
+		formatted[i] = formatPlace(rank)
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
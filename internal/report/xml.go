@@ -0,0 +1,197 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/niklvdanya/BiathlonTracker/internal/config"
+	"github.com/niklvdanya/BiathlonTracker/internal/model"
+	"github.com/niklvdanya/BiathlonTracker/internal/utils"
+)
+
+// XMLReporter writes federation-style XML result documents. Statuses are
+// reported as the usual OK/DNF/DNS/DSQ codes.
+type XMLReporter struct{}
+
+var xmlStatusCodes = map[string]string{
+	model.StatusFinished:     "OK",
+	model.StatusNotFinished:  "DNF",
+	model.StatusNotStarted:   "DNS",
+	model.StatusDisqualified: "DSQ",
+	// Still on course when the log ended.
+	model.StatusRunning: "DNF",
+}
+
+type xmlEventLog struct {
+	XMLName xml.Name   `xml:"EventLog"`
+	Events  []xmlEvent `xml:"Event"`
+}
+
+type xmlEvent struct {
+	Time       string `xml:"time,attr"`
+	ID         int    `xml:"id,attr"`
+	Name       string `xml:"name,attr"`
+	Competitor int    `xml:"competitor,attr"`
+	Params     string `xml:"params,attr,omitempty"`
+}
+
+type xmlResults struct {
+	XMLName     xml.Name        `xml:"Results"`
+	Competition xmlCompetition  `xml:"Competition"`
+	Competitors []xmlCompetitor `xml:"Competitor"`
+}
+
+type xmlCompetition struct {
+	Date          string `xml:"date,attr,omitempty"`
+	Start         string `xml:"start,attr"`
+	StartDelta    string `xml:"startDelta,attr"`
+	Laps          int    `xml:"laps,attr"`
+	LapLength     int    `xml:"lapLength,attr"`
+	PenaltyLength int    `xml:"penaltyLength,attr"`
+	FiringLines   int    `xml:"firingLines,attr"`
+}
+
+type xmlCompetitor struct {
+	ID        int         `xml:"id,attr"`
+	Rank      int         `xml:"rank,attr,omitempty"`
+	Status    string      `xml:"status,attr"`
+	Comment   string      `xml:"comment,attr,omitempty"`
+	TotalTime string      `xml:"TotalTime,omitempty"`
+	Behind    string      `xml:"Behind,omitempty"`
+	Shooting  xmlShooting `xml:"Shooting"`
+	Penalty   *xmlPenalty `xml:"Penalty"`
+	Laps      []xmlLap    `xml:"Lap"`
+}
+
+type xmlShooting struct {
+	Misses int    `xml:"misses,attr"`
+	Stages string `xml:",chardata"`
+}
+
+type xmlPenalty struct {
+	Time  string `xml:"time,attr"`
+	Speed string `xml:"speed,attr"`
+	Loops int    `xml:"loops,attr"`
+}
+
+type xmlLap struct {
+	Number      int    `xml:"number,attr"`
+	Time        string `xml:"time,attr,omitempty"`
+	Speed       string `xml:"speed,attr,omitempty"`
+	Rank        int    `xml:"rank,attr,omitempty"`
+	Finish      string `xml:"finish,attr,omitempty"`
+	ToRange     string `xml:"toRange,attr,omitempty"`
+	RangeTime   string `xml:"rangeTime,attr,omitempty"`
+	PenaltyTime string `xml:"penaltyTime,attr,omitempty"`
+	SkiTime     string `xml:"skiTime,attr,omitempty"`
+}
+
+func (r *XMLReporter) OutputLog(w io.Writer, result model.ProcessResult) error {
+	log := xmlEventLog{Events: make([]xmlEvent, len(result.OutputLog))}
+	for i, event := range result.OutputLog {
+		log.Events[i] = xmlEvent{
+			Time:       utils.FormatTimeRFC(event.Time),
+			ID:         event.EventID,
+			Name:       getEventName(event.EventID),
+			Competitor: event.CompetitorID,
+			Params:     event.ExtraParams,
+		}
+	}
+	return writeXML(w, log)
+}
+
+func (r *XMLReporter) OutputFinalReport(w io.Writer, result model.ProcessResult, cfg config.Config) error {
+	results := xmlResults{
+		Competition: xmlCompetition{
+			Date:          cfg.Date,
+			Start:         cfg.Start,
+			StartDelta:    cfg.StartDelta,
+			Laps:          cfg.Laps,
+			LapLength:     cfg.LapLen,
+			PenaltyLength: cfg.PenaltyLen,
+			FiringLines:   cfg.FiringLines,
+		},
+	}
+
+	for _, standing := range computeStandings(result.Competitors) {
+		results.Competitors = append(results.Competitors, newXMLCompetitor(standing, cfg))
+	}
+
+	return writeXML(w, results)
+}
+
+func newXMLCompetitor(standing Standing, cfg config.Config) xmlCompetitor {
+	comp := standing.Competitor
+	competitor := xmlCompetitor{
+		ID:      comp.ID,
+		Rank:    standing.Place,
+		Status:  xmlStatusCode(comp.Status),
+		Comment: comp.StatusComment,
+		Shooting: xmlShooting{
+			Misses: comp.Misses(),
+			Stages: shootingString(comp.Firings),
+		},
+	}
+
+	if comp.IsFinished() {
+		competitor.TotalTime = utils.FormatDuration(comp.TotalTime())
+		competitor.Behind = formatPositiveDuration(standing.Gap)
+	}
+
+	if penaltyTime := comp.PenaltyTime(); penaltyTime > 0 {
+		competitor.Penalty = &xmlPenalty{
+			Time:  utils.FormatDuration(penaltyTime),
+			Speed: formatSpeed(comp.PenaltySpeed(cfg.PenaltyLen)),
+			Loops: comp.Misses(),
+		}
+	}
+
+	for i, lap := range comp.LapTimes {
+		lapResult := xmlLap{Number: i + 1, Rank: standing.LapRanks[i]}
+		if lap.Time > 0 {
+			lapResult.Time = utils.FormatDuration(lap.Time)
+			lapResult.Speed = formatSpeed(lap.Speed)
+			lapResult.Finish = utils.FormatTimeRFC(lap.Finish)
+			lapResult.ToRange = formatPositiveDuration(lap.ToRange)
+			lapResult.RangeTime = formatPositiveDuration(lap.RangeTime)
+			lapResult.PenaltyTime = formatPositiveDuration(lap.PenaltyTime)
+			lapResult.SkiTime = formatPositiveDuration(lap.SkiTime)
+		}
+		competitor.Laps = append(competitor.Laps, lapResult)
+	}
+
+	return competitor
+}
+
+func xmlStatusCode(status string) string {
+	if code, ok := xmlStatusCodes[status]; ok {
+		return code
+	}
+	return status
+}
+
+// shootingString lists the misses of each shooting stage, e.g. "0 1 0 2".
+func shootingString(firings []model.FiringRecord) string {
+	stages := make([]string, len(firings))
+	for i, firing := range firings {
+		stages[i] = strconv.Itoa(firing.Misses)
+	}
+	return strings.Join(stages, " ")
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -10,6 +10,10 @@ var (
 	ErrInvalidEventFormat  = errors.New("invalid event format")
 	ErrInvalidCompetitorID = errors.New("invalid competitor ID")
 	ErrInvalidEventID      = errors.New("invalid event ID")
+	ErrUnknownEventID      = errors.New("unknown event ID")
+	ErrGeneratedEventID    = errors.New("event ID is only generated by the processor")
+	ErrInvalidEventParams  = errors.New("invalid event parameters")
+	ErrNoStartDrawn        = errors.New("no start time drawn")
 	ErrConfigNotFound      = errors.New("config file not found")
 	ErrEventsNotFound      = errors.New("events file not found")
 )
@@ -32,3 +36,18 @@ func NewProcessingError(competitorID, eventID int, message string) error {
 		Message:      message,
 	}
 }
+
+type ParseError struct {
+	Line   int
+	Column int
+	Raw    string
+	Err    error
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v: %q", e.Line, e.Column, e.Err, e.Raw)
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
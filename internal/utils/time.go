@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -23,17 +24,119 @@ func FormatDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)
 }
 
-func ParseCompetitionTime(timeStr string) (time.Time, error) {
-	now := time.Now()
-	baseDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+const (
+	DateFormat  = "2006-01-02"
+	clockFormat = "15:04:05.000"
 
-	t, err := time.Parse("15:04:05.000", timeStr)
+	// RolloverThreshold is how far a clock may step backwards before it is
+	// taken to have passed midnight rather than to be slightly out of order.
+	RolloverThreshold = 12 * time.Hour
+)
+
+// Clock anchors the HH:MM:SS.mmm strings used in events and config to the
+// competition date, so that every time in a race shares the same day. A
+// string may also carry an explicit "2006-01-02 " date prefix.
+type Clock struct {
+	date time.Time
+	day  time.Time
+	last time.Time
+}
+
+func NewClock(date time.Time) *Clock {
+	day := midnight(date)
+	return &Clock{
+		date: day,
+		day:  day,
+	}
+}
+
+func (c *Clock) Date() time.Time {
+	return c.date
+}
+
+// Parse anchors clock to the competition date.
+func (c *Clock) Parse(clock string) (time.Time, error) {
+	t, _, err := parseStamp(c.date, clock)
+	return t, err
+}
+
+// Next parses consecutive event timestamps. The clock only moves forward:
+// when a time falls more than RolloverThreshold behind the latest one the
+// day is advanced, so a race can run past midnight.
+func (c *Clock) Next(clock string) (time.Time, error) {
+	t, dated, err := parseStamp(c.day, clock)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	return baseDate.Add(time.Duration(t.Hour())*time.Hour +
+	if !dated && !c.last.IsZero() {
+		t = closestDay(t, c.last)
+	}
+
+	if t.After(c.last) {
+		c.last = t
+		c.day = midnight(t)
+	}
+
+	return t, nil
+}
+
+// Near anchors clock to the day that puts it closest to ref, which is how a
+// drawn start time relates to the moment of the draw.
+func (c *Clock) Near(ref time.Time, clock string) (time.Time, error) {
+	t, dated, err := parseStamp(midnight(ref), clock)
+	if err != nil || dated {
+		return t, err
+	}
+	return closestDay(t, ref), nil
+}
+
+func parseStamp(day time.Time, stamp string) (time.Time, bool, error) {
+	dated := false
+	if dateStr, clock, found := strings.Cut(stamp, " "); found {
+		date, err := time.Parse(DateFormat, dateStr)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %q", ErrInvalidTimeFormat, stamp)
+		}
+		day = date
+		stamp = clock
+		dated = true
+	}
+
+	t, err := time.Parse(clockFormat, stamp)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %q", ErrInvalidTimeFormat, stamp)
+	}
+	return day.Add(sinceMidnight(t)), dated, nil
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func closestDay(t, ref time.Time) time.Time {
+	switch {
+	case t.Before(ref.Add(-RolloverThreshold)):
+		return t.AddDate(0, 0, 1)
+	case t.After(ref.Add(RolloverThreshold)):
+		return t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// ParseClockDuration reads a HH:MM:SS clock string, with optional
+// milliseconds, as a duration such as the start delta.
+func ParseClockDuration(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04:05", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTimeFormat, clock)
+	}
+	return sinceMidnight(t), nil
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour +
 		time.Duration(t.Minute())*time.Minute +
 		time.Duration(t.Second())*time.Second +
-		time.Duration(t.Nanosecond())), nil
+		time.Duration(t.Nanosecond())
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -63,52 +64,152 @@ func TestFormatDuration(t *testing.T) {
 	}
 }
 
-func TestParseCompetitionTime(t *testing.T) {
+func TestClockParse(t *testing.T) {
+	clock := NewClock(time.Date(2025, 3, 14, 18, 45, 0, 0, time.Local))
+
 	cases := []struct {
 		input    string
-		expected string
+		expected time.Time
 		wantErr  bool
 	}{
 		{
 			input:    "10:15:30.500",
-			expected: "10:15:30.500",
-			wantErr:  false,
+			expected: time.Date(2025, 3, 14, 10, 15, 30, 500*1000000, time.UTC),
 		},
 		{
-			input:    "09:05:00.001",
-			expected: "09:05:00.001",
-			wantErr:  false,
+			input:    "00:00:00.000",
+			expected: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
 		},
 		{
 			input:    "23:59:59.999",
-			expected: "23:59:59.999",
-			wantErr:  false,
+			expected: time.Date(2025, 3, 14, 23, 59, 59, 999*1000000, time.UTC),
+		},
+		{
+			input:   "10:15:30",
+			wantErr: true,
+		},
+		{
+			input:   "invalid",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		result, err := clock.Parse(c.input)
+
+		if c.wantErr {
+			if !errors.Is(err, ErrInvalidTimeFormat) {
+				t.Errorf("Clock.Parse(%s): expected ErrInvalidTimeFormat, got %v", c.input, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Clock.Parse(%s): unexpected error: %v", c.input, err)
+			continue
+		}
+
+		if !result.Equal(c.expected) {
+			t.Errorf("Clock.Parse(%s): expected %v, got %v", c.input, c.expected, result)
+		}
+	}
+}
+
+func TestParseClockDuration(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{
+			input:    "00:01:30",
+			expected: 90 * time.Second,
+		},
+		{
+			input:    "01:00:00.250",
+			expected: time.Hour + 250*time.Millisecond,
 		},
 		{
-			input:    "invalid",
-			expected: "",
-			wantErr:  true,
+			input:   "90s",
+			wantErr: true,
 		},
 	}
 
 	for _, c := range cases {
-		result, err := ParseCompetitionTime(c.input)
+		result, err := ParseClockDuration(c.input)
 
 		if c.wantErr {
 			if err == nil {
-				t.Errorf("ParseCompetitionTime(%s): expected error, got nil", c.input)
+				t.Errorf("ParseClockDuration(%s): expected error, got nil", c.input)
 			}
 			continue
 		}
 
 		if err != nil {
-			t.Errorf("ParseCompetitionTime(%s): unexpected error: %v", c.input, err)
+			t.Errorf("ParseClockDuration(%s): unexpected error: %v", c.input, err)
 			continue
 		}
 
-		formatted := FormatTimeRFC(result)
-		if formatted != c.expected {
-			t.Errorf("ParseCompetitionTime(%s): expected %s, got %s", c.input, c.expected, formatted)
+		if result != c.expected {
+			t.Errorf("ParseClockDuration(%s): expected %v, got %v", c.input, c.expected, result)
+		}
+	}
+}
+
+func TestClockNextRollsOverMidnight(t *testing.T) {
+	clock := NewClock(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC))
+
+	cases := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"23:30:00.000", time.Date(2025, 3, 14, 23, 30, 0, 0, time.UTC)},
+		{"23:59:59.000", time.Date(2025, 3, 14, 23, 59, 59, 0, time.UTC)},
+		{"00:00:01.000", time.Date(2025, 3, 15, 0, 0, 1, 0, time.UTC)},
+		{"23:59:58.000", time.Date(2025, 3, 14, 23, 59, 58, 0, time.UTC)},
+		{"00:20:00.000", time.Date(2025, 3, 15, 0, 20, 0, 0, time.UTC)},
+		{"00:19:00.000", time.Date(2025, 3, 15, 0, 19, 0, 0, time.UTC)},
+		{"2025-03-20 09:00:00.000", time.Date(2025, 3, 20, 9, 0, 0, 0, time.UTC)},
+		{"09:30:00.000", time.Date(2025, 3, 20, 9, 30, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		result, err := clock.Next(c.input)
+		if err != nil {
+			t.Fatalf("Clock.Next(%s): unexpected error: %v", c.input, err)
+		}
+
+		if !result.Equal(c.expected) {
+			t.Errorf("Clock.Next(%s): expected %v, got %v", c.input, c.expected, result)
+		}
+	}
+
+	if _, err := clock.Next("2025-13-01 09:00:00.000"); !errors.Is(err, ErrInvalidTimeFormat) {
+		t.Errorf("Clock.Next: expected ErrInvalidTimeFormat for a bad date prefix, got %v", err)
+	}
+}
+
+func TestClockNear(t *testing.T) {
+	clock := NewClock(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC))
+	ref := time.Date(2025, 3, 14, 23, 45, 0, 0, time.UTC)
+
+	cases := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"23:55:00.000", time.Date(2025, 3, 14, 23, 55, 0, 0, time.UTC)},
+		{"00:05:00.000", time.Date(2025, 3, 15, 0, 5, 0, 0, time.UTC)},
+		{"2025-03-16 10:00:00.000", time.Date(2025, 3, 16, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		result, err := clock.Near(ref, c.input)
+		if err != nil {
+			t.Fatalf("Clock.Near(%s): unexpected error: %v", c.input, err)
+		}
+
+		if !result.Equal(c.expected) {
+			t.Errorf("Clock.Near(%s): expected %v, got %v", c.input, c.expected, result)
 		}
 	}
 }